@@ -0,0 +1,125 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	addedCoresMu sync.RWMutex
+	// addedCores 通过AddCore添加的core, 按添加顺序
+	addedCores []*addedCore
+	// addedCoresGen 添加或移除core时递增, 使附加了字段的core缓存失效
+	addedCoresGen uint64
+)
+
+// addedCore 包装添加的core, 用指针区分多次添加的同一个core
+type addedCore struct {
+	zapcore.Core
+}
+
+// AddCore 将默认logger的日志同时写入c, 返回的函数移除c
+// 写入c的日志已按日志级别过滤并附加了日志字段, c自身的级别同样生效
+// 添加的core在重新初始化后仍然保留, 调用返回的函数后才移除
+//
+//	remove := logs.AddCore(tracingCore)
+//	defer remove()
+func AddCore(c zapcore.Core) (remove func()) {
+	added := &addedCore{c}
+	addedCoresMu.Lock()
+	addedCores = append(addedCores, added)
+	addedCoresGen++
+	addedCoresMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			addedCoresMu.Lock()
+			defer addedCoresMu.Unlock()
+			for i, a := range addedCores {
+				if a == added {
+					addedCores = append(addedCores[:i:i], addedCores[i+1:]...)
+					addedCoresGen++
+					break
+				}
+			}
+		})
+	}
+}
+
+// addedCoresCore 写入时转发到当前添加的所有core, 添加和移除立即生效
+type addedCoresCore struct {
+	fields []zapcore.Field
+	// with 缓存附加了fields的core, 添加或移除core后重新生成
+	with *atomic.Value
+}
+
+// addedCoresWith 附加了字段的core及生成时的addedCoresGen
+type addedCoresWith struct {
+	gen   uint64
+	cores []zapcore.Core
+}
+
+func (c addedCoresCore) cores() []*addedCore {
+	addedCoresMu.RLock()
+	defer addedCoresMu.RUnlock()
+	return addedCores
+}
+
+// withFields 获取附加了fields的core, 添加的core未变化时使用缓存
+func (c addedCoresCore) withFields() []zapcore.Core {
+	addedCoresMu.RLock()
+	added, gen := addedCores, addedCoresGen
+	addedCoresMu.RUnlock()
+
+	if cached, _ := c.with.Load().(*addedCoresWith); cached != nil && cached.gen == gen {
+		return cached.cores
+	}
+	cores := make([]zapcore.Core, len(added))
+	for i, a := range added {
+		cores[i] = a.Core.With(c.fields)
+	}
+	c.with.Store(&addedCoresWith{gen: gen, cores: cores})
+	return cores
+}
+
+func (c addedCoresCore) Enabled(lvl zapcore.Level) bool {
+	for _, a := range c.cores() {
+		if a.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c addedCoresCore) With(fields []zapcore.Field) zapcore.Core {
+	return addedCoresCore{fields: append(c.fields[:len(c.fields):len(c.fields)], fields...), with: new(atomic.Value)}
+}
+
+func (c addedCoresCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if len(c.fields) == 0 {
+		for _, a := range c.cores() {
+			ce = a.Check(ent, ce)
+		}
+		return ce
+	}
+	for _, core := range c.withFields() {
+		ce = core.Check(ent, ce)
+	}
+	return ce
+}
+
+func (c addedCoresCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return nil
+}
+
+func (c addedCoresCore) Sync() error {
+	var firstErr error
+	for _, a := range c.cores() {
+		if err := a.Sync(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,157 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAddCore(t *testing.T) {
+	tests := []struct {
+		name      string
+		coreLevel zapcore.Level
+		write     func(t *testing.T)
+		want      []string
+		check     func(t *testing.T, e observer.LoggedEntry)
+	}{
+		{"global level applies", zapcore.DebugLevel, func(t *testing.T) { Debug("debug"); Info("info") }, []string{"info"}, nil},
+		{"core level applies", zapcore.WarnLevel, func(t *testing.T) { Info("info"); Warn("warn") }, []string{"warn"}, nil},
+		{"fields and caller", zapcore.DebugLevel, func(t *testing.T) { With("k", "v").Named("db").Info("info") }, []string{"info"},
+			func(t *testing.T, e observer.LoggedEntry) {
+				if e.ContextMap()["k"] != "v" || e.LoggerName != "db" || !strings.HasSuffix(e.Caller.File, "addcore_test.go") {
+					t.Errorf("entry = %+v, want the field, name and caller", e)
+				}
+			}},
+		{"stacktrace on error", zapcore.DebugLevel, func(t *testing.T) { Error("error") }, []string{"error"},
+			func(t *testing.T, e observer.LoggedEntry) {
+				if !strings.Contains(e.Stack, "TestAddCore") {
+					t.Errorf("stack = %q, want the error stack trace", e.Stack)
+				}
+			}},
+		{"survives reinit", zapcore.DebugLevel, func(t *testing.T) {
+			if err := InitLogSetting(&LogConfig{Dir: GetLogConf().Dir, FileName: "test", Level: "info", DisableConsole: true}); err != nil {
+				t.Fatal(err)
+			}
+			Info("after reinit")
+		}, []string{"after reinit"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "info"})
+			core, recorded := observer.New(tt.coreLevel)
+			remove := AddCore(core)
+			defer remove()
+			tt.write(t)
+
+			var got []string
+			for _, e := range recorded.All() {
+				if e.Message == "logging config changed" {
+					continue
+				}
+				got = append(got, e.Message)
+				if tt.check != nil {
+					tt.check(t, e)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+
+			// 移除后不再写入, 重复调用不影响其他core
+			other, otherRecorded := observer.New(zapcore.DebugLevel)
+			defer AddCore(other)()
+			remove()
+			remove()
+			n := recorded.Len()
+			Warn("after remove")
+			if recorded.Len() != n || otherRecorded.Len() != 1 {
+				t.Errorf("after remove: removed core %d new entries, other core %d, want 0 and 1", recorded.Len()-n, otherRecorded.Len())
+			}
+		})
+	}
+}
+
+func TestAddCoreConcurrent(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info"})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				With("j", j).Info("concurrent")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				core, _ := observer.New(zapcore.InfoLevel)
+				AddCore(core)()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// withCountingCore 记录With的调用次数
+type withCountingCore struct {
+	zapcore.Core
+	with *int64
+}
+
+func (c withCountingCore) With(fields []zapcore.Field) zapcore.Core {
+	atomic.AddInt64(c.with, 1)
+	return withCountingCore{c.Core.With(fields), c.with}
+}
+
+func TestAddCoreWithCached(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T)
+		wantWith int64
+	}{
+		{"unchanged", func(*testing.T) {}, 1},
+		{"core added", func(t *testing.T) {
+			other, _ := observer.New(zapcore.DebugLevel)
+			t.Cleanup(AddCore(other))
+		}, 2},
+		{"core removed", func(t *testing.T) {
+			other, _ := observer.New(zapcore.DebugLevel)
+			AddCore(other)()
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "info"})
+			var with int64
+			core, recorded := observer.New(zapcore.DebugLevel)
+			defer AddCore(withCountingCore{core, &with})()
+
+			// 附加了字段的logger在添加的core不变时只调用一次With
+			lg := lz().With(String("k", "v"))
+			for i := 0; i < 3; i++ {
+				lg.Info("cached")
+			}
+			tt.change(t)
+			for i := 0; i < 3; i++ {
+				lg.Info("cached")
+			}
+
+			if got := atomic.LoadInt64(&with); got != tt.wantWith {
+				t.Errorf("With calls = %d, want %d", got, tt.wantWith)
+			}
+			entries := recorded.FilterMessage("cached").All()
+			if len(entries) != 6 {
+				t.Fatalf("entries = %d, want 6", len(entries))
+			}
+			for _, e := range entries {
+				if e.ContextMap()["k"] != "v" {
+					t.Errorf("fields = %v, want k", e.ContextMap())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package logs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// archiveInterval lumberjack没有轮转回调, 按固定间隔检查已轮转的文件
+	archiveInterval = time.Minute
+	// backupTimeFormat lumberjack轮转文件名中的时间格式
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// archiver 将轮转后的日志文件移动到归档目录, 并按MaxAge清理归档目录
+type archiver struct {
+	dir        string
+	archiveDir string
+	files      []string
+	// compress lumberjack压缩轮转文件, 只归档压缩完成的.gz文件
+	compress bool
+	// localTime 轮转文件名中的时间为本地时间, 否则为UTC
+	localTime bool
+	// maxAge 归档保存天数, ExtendRetention时在运行中修改
+	maxAge int64
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newArchiver(dir, archiveDir string, maxAge int, compress, localTime bool, files ...string) *archiver {
+	return &archiver{
+		dir:        dir,
+		archiveDir: archiveDir,
+		files:      files,
+		compress:   compress,
+		localTime:  localTime,
+		maxAge:     int64(maxAge),
+	}
+}
+
+// setMaxAge 修改归档保存天数, 下次归档时生效
+func (a *archiver) setMaxAge(days int) {
+	atomic.StoreInt64(&a.maxAge, int64(days))
+}
+
+// start 启动后台归档, 已启动时不做任何事
+func (a *archiver) start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stop != nil {
+		return
+	}
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go a.run(a.stop, a.done)
+}
+
+// close 停止后台归档并等待正在进行的归档完成
+func (a *archiver) close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stop == nil {
+		return
+	}
+	close(a.stop)
+	<-a.done
+	a.stop, a.done = nil, nil
+}
+
+func (a *archiver) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(archiveInterval)
+	defer ticker.Stop()
+	for {
+		a.archive()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (a *archiver) archive() {
+	entries, err := os.ReadDir(a.dir)
+	if err == nil {
+		for _, e := range entries {
+			if e.IsDir() || !a.isBackup(e.Name()) || a.compressing(e.Name()) {
+				continue
+			}
+			if err := os.MkdirAll(a.archiveDir, 0755); err != nil {
+				return
+			}
+			_ = moveFile(filepath.Join(a.dir, e.Name()), filepath.Join(a.archiveDir, e.Name()))
+		}
+	}
+
+	maxAge := atomic.LoadInt64(&a.maxAge)
+	if maxAge <= 0 {
+		return
+	}
+	entries, err = os.ReadDir(a.archiveDir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-time.Duration(maxAge) * 24 * time.Hour)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if t, ok := a.backupTime(e.Name()); ok && t.Before(cutoff) {
+			_ = os.Remove(filepath.Join(a.archiveDir, e.Name()))
+		}
+	}
+}
+
+func (a *archiver) isBackup(name string) bool {
+	_, ok := a.backupTime(name)
+	return ok
+}
+
+// compressing 开启压缩时未压缩的轮转文件和正在压缩的.gz文件都不归档
+// lumberjack压缩完成后才删除原文件
+func (a *archiver) compressing(name string) bool {
+	if !a.compress {
+		return false
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(a.dir, strings.TrimSuffix(name, ".gz")))
+	return err == nil
+}
+
+// backupTime 解析lumberjack轮转文件名 name-2006-01-02T15-04-05.000.log(.gz) 中的时间
+func (a *archiver) backupTime(name string) (time.Time, bool) {
+	name = strings.TrimSuffix(name, ".gz")
+	for _, file := range a.files {
+		ext := filepath.Ext(file)
+		prefix := strings.TrimSuffix(file, ext) + "-"
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := name[len(prefix) : len(name)-len(ext)]
+		loc := time.UTC
+		if a.localTime {
+			loc = time.Local
+		}
+		if t, err := time.ParseInLocation(backupTimeFormat, ts, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// moveFile 移动文件, 跨设备无法重命名时复制后删除原文件
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(src)
+}
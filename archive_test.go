@@ -0,0 +1,103 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestArchiverArchive(t *testing.T) {
+	const (
+		plain      = "test-2024-01-02T03-04-05.000.log"
+		compressed = "test-2024-01-02T03-04-06.000.log.gz"
+		// compressing lumberjack正在压缩, 原文件还存在
+		compressing = "test-2024-01-02T03-04-07.000.log"
+	)
+	tests := []struct {
+		name     string
+		compress bool
+		archived []string
+		kept     []string
+	}{
+		{"uncompressed", false, []string{compressed, compressing + ".gz", compressing, plain}, []string{"test.log"}},
+		{"compressed", true, []string{compressed}, []string{compressing, compressing + ".gz", plain, "test.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, archiveDir := t.TempDir(), t.TempDir()
+			for _, name := range []string{"test.log", plain, compressed, compressing, compressing + ".gz"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("line\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			newArchiver(dir, archiveDir, 0, tt.compress, false, "test.log").archive()
+
+			sort.Strings(tt.archived)
+			sort.Strings(tt.kept)
+			if got := dirNames(t, archiveDir); !reflect.DeepEqual(got, tt.archived) {
+				t.Errorf("archived = %v, want %v", got, tt.archived)
+			}
+			if got := dirNames(t, dir); !reflect.DeepEqual(got, tt.kept) {
+				t.Errorf("kept = %v, want %v", got, tt.kept)
+			}
+		})
+	}
+}
+
+func TestArchiverBackupTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		localTime bool
+		want      *time.Location
+	}{
+		{"UTC", false, time.UTC},
+		{"local time", true, time.Local},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newArchiver("", "", 0, false, tt.localTime, "test.log")
+			got, ok := a.backupTime("test-2024-01-02T03-04-05.000.log.gz")
+			if !ok {
+				t.Fatal("backupTime ok = false, want true")
+			}
+			want := time.Date(2024, 1, 2, 3, 4, 5, 0, tt.want)
+			if !got.Equal(want) || got.Location() != tt.want {
+				t.Errorf("backupTime = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestArchiverMaxAge(t *testing.T) {
+	dir, archiveDir := t.TempDir(), t.TempDir()
+	now := time.Now().UTC()
+	old := "test-" + now.Add(-49*time.Hour).Format(backupTimeFormat) + ".log"
+	recent := "test-" + now.Add(-47*time.Hour).Format(backupTimeFormat) + ".log"
+	for _, name := range []string{old, recent} {
+		if err := os.WriteFile(filepath.Join(archiveDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newArchiver(dir, archiveDir, 2, false, false, "test.log").archive()
+
+	if got := dirNames(t, archiveDir); !reflect.DeepEqual(got, []string{recent}) {
+		t.Errorf("archived = %v, want %v", got, []string{recent})
+	}
+}
+
+// dirNames 返回目录中按名称排序的文件名
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
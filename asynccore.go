@@ -0,0 +1,238 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAsyncQueueSize    = 100
+	defaultAsyncFlushTimeout = 5 * time.Second
+	// asyncExitFlushTimeout panic和fatal级别的日志写入后等待发送的最长时间, 避免远程服务不可用时推迟进程退出
+	asyncExitFlushTimeout = 500 * time.Millisecond
+)
+
+var (
+	asyncCoresMu sync.Mutex
+	// asyncCores AddAsyncCore注册的输出, Drain时同样等待其发送完成
+	asyncCores = map[*AsyncCore]struct{}{}
+)
+
+// AsyncCoreConfig AddAsyncCore使用的配置
+type AsyncCoreConfig struct {
+	Name         string               // 输出名称, 用于Drain返回的错误
+	Level        zapcore.LevelEnabler // 发送的日志级别
+	QueueSize    int                  // 等待发送的消息条数上限, 超过时丢弃新的消息, 默认100
+	FlushTimeout time.Duration        // Sync时等待发送完成的最长时间, 默认5秒
+
+	// Encode 在写日志的goroutine中将日志转为消息, 返回错误时计入失败条数
+	Encode func(ent zapcore.Entry, fields []zapcore.Field) (interface{}, error)
+	// Send 在后台goroutine中依次发送消息, done关闭时应尽快返回, 返回错误时计入失败条数
+	Send func(msg interface{}, done <-chan struct{}) error
+	// Flush 不为nil时在Sync等待队列中的消息发送完成后调用, timeout为剩余的时间
+	Flush func(timeout time.Duration) error
+
+	// Tick 不为nil时每Interval在后台goroutine中调用一次, 与Send不会同时调用, 用于限流周期结束时发送汇总等
+	Tick     func(done <-chan struct{})
+	Interval time.Duration
+}
+
+// AsyncCore AddAsyncCore注册的输出, 日志放入有界队列后由后台goroutine依次发送
+type AsyncCore struct {
+	// failed 发送失败和队列已满丢弃的消息条数, 放在首位保证32位平台上的原子操作对齐
+	failed  uint64
+	conf    AsyncCoreConfig
+	items   chan asyncItem
+	done    chan struct{}
+	stopped chan struct{}
+	remove  func()
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// asyncItem 队列中的消息, flushed不为nil时为Sync的标记, 处理到此处时关闭
+type asyncItem struct {
+	msg     interface{}
+	flushed chan struct{}
+}
+
+// AddAsyncCore 注册在后台goroutine发送日志的输出, 用于Sentry、告警机器人等较慢的远程服务, 重新初始化后依然有效
+// 写入时只放入有界队列, 远程服务不可用时不会阻塞日志输出, 失败时不输出日志, 只计入Failures
+// Sync和Drain时等待发送完成或超时, panic和fatal级别的日志写入后最多等待500毫秒发送, 不再使用时调用Close
+//
+//	ac, err := logs.AddAsyncCore(logs.AsyncCoreConfig{Name: "pager", Level: zapcore.ErrorLevel, Encode: encode, Send: send})
+//	defer ac.Close()
+func AddAsyncCore(conf AsyncCoreConfig) (*AsyncCore, error) {
+	if conf.Level == nil || conf.Encode == nil || conf.Send == nil {
+		return nil, errors.New("logs: async core requires Level, Encode and Send")
+	}
+	if conf.QueueSize < 0 || (conf.Tick != nil && conf.Interval <= 0) {
+		return nil, fmt.Errorf("logs: invalid async core %q, queue size %d interval %v", conf.Name, conf.QueueSize, conf.Interval)
+	}
+	if conf.QueueSize == 0 {
+		conf.QueueSize = defaultAsyncQueueSize
+	}
+	if conf.FlushTimeout <= 0 {
+		conf.FlushTimeout = defaultAsyncFlushTimeout
+	}
+	a := &AsyncCore{
+		conf:    conf,
+		items:   make(chan asyncItem, conf.QueueSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go a.run()
+	a.remove = AddCore(asyncCore{a: a})
+
+	asyncCoresMu.Lock()
+	asyncCores[a] = struct{}{}
+	asyncCoresMu.Unlock()
+	return a, nil
+}
+
+// Failures 发送失败和队列已满丢弃的消息条数
+func (a *AsyncCore) Failures() uint64 {
+	return atomic.LoadUint64(&a.failed)
+}
+
+// Sync 等待队列中的消息发送完成, 超时返回错误
+func (a *AsyncCore) Sync() error {
+	return a.flush(a.conf.FlushTimeout)
+}
+
+// flush 等待队列中的消息发送完成, 最多等待timeout
+func (a *AsyncCore) flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	flushed := make(chan struct{})
+	select {
+	case a.items <- asyncItem{flushed: flushed}:
+	case <-timer.C:
+		return fmt.Errorf("logs: %s flush timed out", a.conf.Name)
+	case <-a.stopped:
+		return nil
+	}
+	select {
+	case <-flushed:
+	case <-timer.C:
+		return fmt.Errorf("logs: %s flush timed out", a.conf.Name)
+	case <-a.stopped:
+		return nil
+	}
+	if a.conf.Flush != nil {
+		return a.conf.Flush(time.Until(deadline))
+	}
+	return nil
+}
+
+// Close 移除该输出, 等待队列中的消息发送完成或超时后停止后台goroutine, 重复调用时返回第一次的结果
+func (a *AsyncCore) Close() error {
+	a.closeOnce.Do(func() {
+		a.remove()
+		asyncCoresMu.Lock()
+		delete(asyncCores, a)
+		asyncCoresMu.Unlock()
+
+		a.closeErr = a.Sync()
+		close(a.done)
+		<-a.stopped
+	})
+	return a.closeErr
+}
+
+func (a *AsyncCore) run() {
+	defer close(a.stopped)
+
+	var tick <-chan time.Time
+	if a.conf.Tick != nil {
+		ticker := time.NewTicker(a.conf.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case item := <-a.items:
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			if err := a.conf.Send(item.msg, a.done); err != nil {
+				atomic.AddUint64(&a.failed, 1)
+			}
+		case <-tick:
+			a.conf.Tick(a.done)
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// push 将消息放入队列, 队列已满时丢弃
+func (a *AsyncCore) push(msg interface{}) {
+	select {
+	case a.items <- asyncItem{msg: msg}:
+	default:
+		atomic.AddUint64(&a.failed, 1)
+	}
+}
+
+// registeredAsyncCores AddAsyncCore注册的输出, 用于Drain
+func registeredAsyncCores() []asyncSink {
+	asyncCoresMu.Lock()
+	defer asyncCoresMu.Unlock()
+	sinks := make([]asyncSink, 0, len(asyncCores))
+	for a := range asyncCores {
+		sinks = append(sinks, asyncSink{name: a.conf.Name, sync: a.Sync})
+	}
+	return sinks
+}
+
+// asyncCore 将日志转为消息放入AsyncCore的队列
+type asyncCore struct {
+	a      *AsyncCore
+	fields []zapcore.Field
+}
+
+func (c asyncCore) Enabled(lvl zapcore.Level) bool {
+	return c.a.conf.Level.Enabled(lvl)
+}
+
+func (c asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return asyncCore{c.a, append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	msg, err := c.a.conf.Encode(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+	if err != nil {
+		atomic.AddUint64(&c.a.failed, 1)
+	} else {
+		c.a.push(msg)
+	}
+	// panic和fatal级别的日志之后进程可能退出, 写入后等待发送, 但不超过asyncExitFlushTimeout
+	if ent.Level >= zapcore.PanicLevel {
+		timeout := c.a.conf.FlushTimeout
+		if timeout > asyncExitFlushTimeout {
+			timeout = asyncExitFlushTimeout
+		}
+		return c.a.flush(timeout)
+	}
+	return nil
+}
+
+func (c asyncCore) Sync() error {
+	return c.a.Sync()
+}
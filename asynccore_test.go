@@ -0,0 +1,217 @@
+package logs
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSender 记录发送的消息, block不为nil时发送前等待其关闭
+type recordingSender struct {
+	mu    sync.Mutex
+	msgs  []string
+	block chan struct{}
+	err   error
+}
+
+func (s *recordingSender) send(msg interface{}, done <-chan struct{}) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-done:
+			return errors.New("closed")
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, msg.(string))
+	return s.err
+}
+
+func (s *recordingSender) sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.msgs...)
+}
+
+func encodeMessage(ent zapcore.Entry, fields []zapcore.Field) (interface{}, error) {
+	return ent.Message, nil
+}
+
+func TestAsyncCore(t *testing.T) {
+	tests := []struct {
+		name         string
+		queueSize    int
+		sendErr      error
+		block        bool
+		writes       []string
+		wantSent     []string
+		wantFailures uint64
+	}{
+		{"sends in order", 0, nil, false, []string{"a", "b", "c"}, []string{"a", "b", "c"}, 0},
+		{"send errors counted", 0, errors.New("down"), false, []string{"a", "b"}, []string{"a", "b"}, 2},
+		// 第一条消息阻塞在发送中, 队列只能再放入2条
+		{"queue full drops", 2, nil, true, []string{"a", "b", "c", "d", "e"}, []string{"a", "b", "c"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "warn", DisableFile: true})
+			s := &recordingSender{err: tt.sendErr}
+			if tt.block {
+				s.block = make(chan struct{})
+			}
+			ac, err := AddAsyncCore(AsyncCoreConfig{Name: "test", Level: zapcore.ErrorLevel, QueueSize: tt.queueSize, Encode: encodeMessage, Send: s.send})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ac.Close()
+
+			Warn("below level")
+			for i, msg := range tt.writes {
+				Error(msg)
+				if i == 0 && tt.block {
+					// 等待第一条消息被取出
+					time.Sleep(20 * time.Millisecond)
+				}
+			}
+			if s.block != nil {
+				close(s.block)
+			}
+			if err := Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if got := strings.Join(s.sent(), ","); got != strings.Join(tt.wantSent, ",") {
+				t.Errorf("sent = %q, want %q", got, tt.wantSent)
+			}
+			if got := ac.Failures(); got != tt.wantFailures {
+				t.Errorf("Failures() = %d, want %d", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestAsyncCoreDrainAndClose(t *testing.T) {
+	initTestLogger(t, &LogConfig{DisableFile: true})
+	s := &recordingSender{block: make(chan struct{})}
+	ac, err := AddAsyncCore(AsyncCoreConfig{Name: "slow", Level: zapcore.ErrorLevel, FlushTimeout: 50 * time.Millisecond, Encode: encodeMessage, Send: s.send})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Error("stuck")
+	if err := Drain(time.Second); err == nil || !strings.Contains(err.Error(), "slow") {
+		t.Errorf("Drain with a blocked sink = %v, want an error naming it", err)
+	}
+
+	close(s.block)
+	if err := Drain(time.Second); err != nil {
+		t.Errorf("Drain after unblocking = %v", err)
+	}
+	if err := ac.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	Error("after close")
+	if err := Drain(time.Second); err != nil {
+		t.Errorf("Drain after Close = %v, want the sink unregistered", err)
+	}
+	if got := s.sent(); len(got) != 1 {
+		t.Errorf("sent = %q, want only the entry before Close", got)
+	}
+}
+
+func TestAsyncCoreExitFlush(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   zapcore.Level
+		blocked bool
+		wantErr bool
+	}{
+		{"error not flushed", zapcore.ErrorLevel, true, false},
+		{"dpanic not flushed", zapcore.DPanicLevel, true, false},
+		{"panic flushed", zapcore.PanicLevel, false, false},
+		{"fatal flushed", zapcore.FatalLevel, false, false},
+		{"panic bounded", zapcore.PanicLevel, true, true},
+		{"fatal bounded", zapcore.FatalLevel, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &recordingSender{}
+			if tt.blocked {
+				s.block = make(chan struct{})
+			}
+			ac, err := AddAsyncCore(AsyncCoreConfig{Name: "exit", Level: zapcore.ErrorLevel, Encode: encodeMessage, Send: s.send})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ac.Close()
+			if s.block != nil {
+				defer close(s.block)
+			}
+
+			// 远程服务不可用时只等待asyncExitFlushTimeout, 而不是默认的FlushTimeout
+			start := time.Now()
+			err = asyncCore{a: ac}.Write(zapcore.Entry{Level: tt.level, Message: "exit line"}, nil)
+			elapsed := time.Since(start)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Write = %v, wantErr %v", err, tt.wantErr)
+			}
+			if elapsed >= defaultAsyncFlushTimeout/2 {
+				t.Errorf("Write took %v, want well under FlushTimeout %v", elapsed, defaultAsyncFlushTimeout)
+			}
+			if tt.wantErr && elapsed < asyncExitFlushTimeout {
+				t.Errorf("Write took %v, want it to wait %v", elapsed, asyncExitFlushTimeout)
+			}
+			if tt.level >= zapcore.PanicLevel && !tt.blocked {
+				if got := s.sent(); len(got) != 1 {
+					t.Errorf("sent = %q, want the entry sent before Write returns", got)
+				}
+			}
+		})
+	}
+}
+
+func TestAsyncCoreTick(t *testing.T) {
+	ticks := make(chan struct{}, 1)
+	ac, err := AddAsyncCore(AsyncCoreConfig{
+		Name:   "tick",
+		Level:  zapcore.ErrorLevel,
+		Encode: encodeMessage,
+		Send:   (&recordingSender{}).send,
+		Tick: func(done <-chan struct{}) {
+			select {
+			case ticks <- struct{}{}:
+			default:
+			}
+		},
+		Interval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ac.Close()
+	select {
+	case <-ticks:
+	case <-time.After(time.Second):
+		t.Fatal("Tick not called")
+	}
+}
+
+func TestAddAsyncCoreInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		conf AsyncCoreConfig
+	}{
+		{"missing send", AsyncCoreConfig{Level: zapcore.ErrorLevel, Encode: encodeMessage}},
+		{"missing level", AsyncCoreConfig{Encode: encodeMessage, Send: (&recordingSender{}).send}},
+		{"tick without interval", AsyncCoreConfig{Level: zapcore.ErrorLevel, Encode: encodeMessage, Send: (&recordingSender{}).send, Tick: func(<-chan struct{}) {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := AddAsyncCore(tt.conf); err == nil {
+				t.Error("AddAsyncCore succeeded, want error")
+			}
+		})
+	}
+}
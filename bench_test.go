@@ -0,0 +1,29 @@
+package logs
+
+import (
+	"io"
+	"testing"
+)
+
+// BenchmarkTypedFields 三个字段时InfoF与Infow的开销, InfoF不经过sugar, 省去键值对转换为字段的开销
+//
+//	go test -run=^$ -bench=TypedFields -benchmem
+func BenchmarkTypedFields(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		log  func()
+	}{
+		{"InfoF", func() { InfoF("request done", String("path", "/users"), Int("status", 200), Int64("cost", 12)) }},
+		{"Infow", func() { Infow("request done", "path", "/users", "status", 200, "cost", int64(12)) }},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			initTestLogger(b, &LogConfig{Level: "info", DisableFile: true, Writers: []io.Writer{io.Discard}})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bm.log()
+			}
+		})
+	}
+}
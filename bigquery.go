@@ -0,0 +1,63 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// bigQueryEncoder 输出固定列的NDJSON, 便于导入BigQuery
+// 列为timestamp severity message caller logger stacktrace fields, 每列总是输出,
+// 所有结构化字段编码为json字符串放在fields列中, 避免动态字段导致表结构膨胀
+type bigQueryEncoder struct {
+	// Encoder 只编码字段的json encoder, 保存With添加的字段
+	zapcore.Encoder
+	entry zapcore.Encoder
+}
+
+func newBigQueryEncoder(lineEnding string) zapcore.Encoder {
+	fieldsConfig := zapcore.EncoderConfig{
+		SkipLineEnding: true,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	entryConfig := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "severity",
+		MessageKey:     "message",
+		LineEnding:     lineEnding,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeLevel:    customLevelEncoder(traceLevelEncoder(zapcore.CapitalLevelEncoder, "")),
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+	return bigQueryEncoder{
+		Encoder: zapcore.NewJSONEncoder(fieldsConfig),
+		entry:   zapcore.NewJSONEncoder(entryConfig),
+	}
+}
+
+func (e bigQueryEncoder) Clone() zapcore.Encoder {
+	return bigQueryEncoder{Encoder: e.Encoder.Clone(), entry: e.entry}
+}
+
+func (e bigQueryEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.String()
+	buf.Free()
+
+	caller := ""
+	if ent.Caller.Defined {
+		caller = ent.Caller.TrimmedPath()
+	}
+	columns := []zapcore.Field{
+		zap.String("caller", caller),
+		zap.String("logger", ent.LoggerName),
+		zap.String("stacktrace", ent.Stack),
+		zap.String("fields", data),
+	}
+	ent.Caller, ent.LoggerName, ent.Stack = zapcore.EntryCaller{}, "", ""
+	return e.entry.EncodeEntry(ent, columns)
+}
@@ -0,0 +1,99 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBigQueryEncoderGolden(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	caller := zapcore.NewEntryCaller(0, "/src/github.com/xpfo-go/logs/orders/handler.go", 42, true)
+	tests := []struct {
+		name   string
+		with   []zapcore.Field
+		ent    zapcore.Entry
+		fields []zapcore.Field
+	}{
+		{"minimal", nil, zapcore.Entry{Level: zapcore.InfoLevel, Time: ts, Message: "started"}, nil},
+		{"all columns", nil, zapcore.Entry{Level: zapcore.ErrorLevel, Time: ts, Message: "query failed", LoggerName: "db",
+			Caller: caller, Stack: "main.run\n\t/src/main.go:10"},
+			[]zapcore.Field{zap.String("table", "orders"), zap.Int("rows", 3), zap.Duration("cost", 1500*time.Millisecond), zap.Error(errors.New("timeout"))}},
+		{"With fields", []zapcore.Field{zap.String("request_id", "r-1")}, zapcore.Entry{Level: zapcore.WarnLevel, Time: ts, Message: "slow"},
+			[]zapcore.Field{zap.Bool("retry", true), zap.Object("user", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				enc.AddString("name", "bob")
+				return nil
+			}))}},
+		{"trace level", nil, zapcore.Entry{Level: TraceLevel, Time: ts, Message: "tick"}, []zapcore.Field{zap.Time("at", ts)}},
+		{"quoted message", nil, zapcore.Entry{Level: zapcore.DebugLevel, Time: ts, Message: `say "hi"` + "\n"}, []zapcore.Field{zap.String("s", `a"b`)}},
+	}
+	var got strings.Builder
+	for _, tt := range tests {
+		enc := newBigQueryEncoder("\n")
+		if len(tt.with) > 0 {
+			enc = enc.Clone()
+			for _, f := range tt.with {
+				f.AddTo(enc)
+			}
+		}
+		buf, err := enc.EncodeEntry(tt.ent, tt.fields)
+		if err != nil {
+			t.Fatalf("%s: EncodeEntry: %v", tt.name, err)
+		}
+		got.WriteString(buf.String())
+		buf.Free()
+	}
+
+	want, err := os.ReadFile("testdata/bigquery.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("encoded =\n%s\nwant\n%s", got.String(), want)
+	}
+	// 每行列固定, fields列为json字符串
+	for _, line := range strings.Split(strings.TrimSpace(got.String()), "\n") {
+		var row map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			t.Fatalf("line %s: %v", line, err)
+		}
+		for _, col := range []string{"timestamp", "severity", "message", "caller", "logger", "stacktrace", "fields"} {
+			if _, ok := row[col]; !ok {
+				t.Errorf("line %s missing column %s", line, col)
+			}
+		}
+		if len(row) != 7 {
+			t.Errorf("line %s has %d columns, want 7", line, len(row))
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(row["fields"].(string)), &fields); err != nil {
+			t.Errorf("fields column %v is not a json object: %v", row["fields"], err)
+		}
+	}
+}
+
+func TestBigQueryEncoderLineEnding(t *testing.T) {
+	tests := []struct {
+		lineEnding string
+		want       string
+	}{
+		{"", "\n"},
+		{"\n", "\n"},
+		{"\r\n", "\r\n"},
+	}
+	for _, tt := range tests {
+		buf, err := newBigQueryEncoder(tt.lineEnding).EncodeEntry(zapcore.Entry{Message: "line"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); !strings.HasSuffix(got, "}"+tt.want) {
+			t.Errorf("line ending %q: encoded %q, want suffix %q", tt.lineEnding, got, tt.want)
+		}
+		buf.Free()
+	}
+}
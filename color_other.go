@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package logs
+
+func enableColor() bool {
+	return true
+}
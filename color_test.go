@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnableColor(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		conf      LogConfig
+		wantColor bool
+	}{
+		{"enabled", true, LogConfig{}, true},
+		{"disabled", false, LogConfig{}, false},
+		{"disabled with theme", false, LogConfig{Theme: "dark"}, false},
+		{"enabled with emoji", true, LogConfig{Emoji: true}, true},
+		{"disabled with emoji", false, LogConfig{Emoji: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := enableColorFn
+			enableColorFn = func() bool { return tt.enabled }
+			defer func() { enableColorFn = prev }()
+
+			c := tt.conf
+			c.Level = "trace"
+			stdout, stderr := initConsoleLogger(t, &c)
+			Trace("trace line")
+			Info("info line")
+			Error("error line")
+			_ = Sync()
+
+			out := stdout() + stderr()
+			for _, want := range []string{"TRACE", "INFO", "ERROR"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("console = %q, want %s", out, want)
+				}
+			}
+			if got := strings.Contains(out, "\x1b["); got != tt.wantColor {
+				t.Errorf("console colored = %v, want %v:\n%q", got, tt.wantColor, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+// +build windows
+
+package logs
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// enableColor 为stdout和stderr开启虚拟终端处理以支持ANSI颜色, 不支持时返回false
+func enableColor() bool {
+	for _, h := range []windows.Handle{windows.Stdout, windows.Stderr} {
+		var mode uint32
+		if err := windows.GetConsoleMode(h, &mode); err != nil {
+			return false
+		}
+		if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+			continue
+		}
+		if err := windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			return false
+		}
+	}
+	return true
+}
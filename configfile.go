@@ -0,0 +1,107 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// InitLogSettingFromFile 从YAML或JSON文件读取配置并初始化日志, .json文件按JSON解析, 其余按YAML解析
+// 键名与LogConfig的字段名对应, 不区分大小写, 可使用下划线, 如max_age; 时间间隔可写为"5s"
+// 未知的键不会导致失败, 初始化后以warn级别输出; 文件不存在时返回的错误满足errors.Is(err, os.ErrNotExist)
+func InitLogSettingFromFile(path string) error {
+	c, unknown, err := loadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if err := InitLogSetting(c); err != nil {
+		return err
+	}
+	for _, key := range unknown {
+		l().Warnw("unknown logging config key", "key", key, "file", path)
+	}
+	return nil
+}
+
+// loadConfigFile 读取配置文件, 返回配置和未知的键
+func loadConfigFile(path string) (*LogConfig, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logs: read config: %w", err)
+	}
+	var doc map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("logs: parse config %s: %w", path, err)
+	}
+
+	c := defaultLogConfig()
+	cv := reflect.ValueOf(c).Elem()
+	var unknown []string
+	for key, value := range doc {
+		field, ok := configField(cv, key)
+		if !ok {
+			unknown = append(unknown, key)
+			continue
+		}
+		if err := setConfigField(field, value); err != nil {
+			return nil, nil, fmt.Errorf("logs: invalid %s in %s: %w", key, path, err)
+		}
+	}
+	return c, unknown, nil
+}
+
+// configField 按键名查找配置字段, 忽略大小写和下划线
+func configField(cv reflect.Value, key string) (reflect.Value, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	key = normalize(key)
+	rt := cv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" || f.Type.Kind() == reflect.Func {
+			continue
+		}
+		if normalize(f.Name) == key {
+			return cv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setConfigField(field reflect.Value, value interface{}) error {
+	if s, ok := value.(string); ok && field.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	// 通过json转换为字段的类型
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(field.Type())
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return err
+	}
+	field.Set(ptr.Elem())
+	return nil
+}
@@ -0,0 +1,93 @@
+package logs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"yaml", "testdata/logs.yaml", ""},
+		{"json", "testdata/logs.json", ""},
+		{"malformed", "testdata/malformed.yaml", "parse config"},
+		{"missing", "testdata/missing.yaml", "read config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, unknown, err := loadConfigFile(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(unknown) != 0 {
+				t.Errorf("unknown keys = %v", unknown)
+			}
+			if c.FileName != "api" || c.Level != "info" || c.MaxSize != 50 || c.MaxAge != 7 || c.Dir != "/var/log/api" || c.Encoding != "json" {
+				t.Errorf("config = %+v", c)
+			}
+			if c.PeriodicFsync != 5*time.Second || c.SplitErrorFile == nil || *c.SplitErrorFile || c.Levels["db"] != "warn" {
+				t.Errorf("PeriodicFsync = %v, SplitErrorFile = %v, Levels = %v", c.PeriodicFsync, c.SplitErrorFile, c.Levels)
+			}
+			// 未设置的字段使用默认值
+			if !c.LocalTime {
+				t.Error("LocalTime = false, want the default")
+			}
+		})
+	}
+}
+
+func TestInitLogSettingFromFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		want         string
+		wantErr      string
+		wantNotExist bool
+	}{
+		{"unknown key warns", "file_name: test\nlevel: info\ndisable_console: true\nverbosity: 3\n", `"key": "verbosity"`, "", false},
+		{"invalid value", "file_name: test\nmax_age: forever\n", "", "invalid max_age", false},
+		{"missing file", "", "", "read config", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			path := filepath.Join(dir, "logs.yaml")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content+"dir: "+dir+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := InitLogSettingFromFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				// 文件不存在时调用方可以回退到默认配置
+				if errors.Is(err, os.ErrNotExist) != tt.wantNotExist {
+					t.Errorf("errors.Is(err, os.ErrNotExist) = %v, want %v", !tt.wantNotExist, tt.wantNotExist)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = Sync()
+			if got := readLog(t, filepath.Join(dir, "test.log")); !strings.Contains(got, "unknown logging config key") || !strings.Contains(got, tt.want) {
+				t.Errorf("log = %q, want the unknown key warning", got)
+			}
+		})
+	}
+}
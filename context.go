@@ -0,0 +1,162 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+type ctxFieldsKey struct{}
+
+type ctxLoggerKey struct{}
+
+type ctxStartKey struct{}
+
+var (
+	ctxKeysMu sync.RWMutex
+	ctxKeys   []interface{}
+)
+
+// RegisterContextKeys 注册需要输出到日志的context key, 字段名为fmt.Sprint(key)
+func RegisterContextKeys(keys ...interface{}) {
+	ctxKeysMu.Lock()
+	defer ctxKeysMu.Unlock()
+	ctxKeys = append(ctxKeys, keys...)
+}
+
+// NewContext 将logger保存到context中, 通过FromContext获取
+func NewContext(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, logger)
+}
+
+// FromContext 获取NewContext保存在context中的logger, 不存在时返回全局logger
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	if logger, ok := ctx.Value(ctxLoggerKey{}).(*zap.SugaredLogger); ok && logger != nil {
+		return logger
+	}
+	return userLogger()
+}
+
+// WithContext 获取附加了context中日志字段的logger, context中保存了logger时以其为基础
+func WithContext(ctx context.Context) *zap.SugaredLogger {
+	return FromContext(ctx).With(contextFields(ctx)...)
+}
+
+func DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l().With(contextFields(ctx)...).Debugw(msg, keysAndValues...)
+}
+
+func InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l().With(contextFields(ctx)...).Infow(msg, keysAndValues...)
+}
+
+func WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l().With(contextFields(ctx)...).Warnw(msg, keysAndValues...)
+}
+
+func ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l().With(contextFields(ctx)...).Errorw(msg, keysAndValues...)
+}
+
+// ContextWithStart 在context中记录操作的开始时间, LogContextDone据此输出操作已执行的时间
+func ContextWithStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxStartKey{}, time.Now())
+}
+
+// contextStart 获取ContextWithStart或StartRequest记录的开始时间
+func contextStart(ctx context.Context) (time.Time, bool) {
+	if start, ok := ctx.Value(ctxStartKey{}).(time.Time); ok {
+		return start, true
+	}
+	if r := RequestFromContext(ctx); r != nil {
+		return r.start, true
+	}
+	return time.Time{}, false
+}
+
+// LogContextDone 输出context结束的原因, context未结束时不输出
+// 超时以warn级别输出截止时间和已超出的时间, 取消以debug级别输出
+// 通过context.WithCancelCause等设置了原因时输出cause, 通过ContextWithStart或StartRequest记录了开始时间时输出elapsed
+func LogContextDone(ctx context.Context, op string) {
+	err := ctx.Err()
+	if err == nil {
+		return
+	}
+	kv := []interface{}{"op", op, "reason", err.Error()}
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		kv = append(kv, "cause", cause.Error())
+	}
+	if start, ok := contextStart(ctx); ok {
+		kv = append(kv, "elapsed", time.Since(start))
+	}
+	logger := l().With(contextFields(ctx)...)
+	if errors.Is(err, context.DeadlineExceeded) {
+		if deadline, ok := ctx.Deadline(); ok {
+			kv = append(kv, "deadline", deadline, "overdue", time.Since(deadline))
+		}
+		logger.Warnw(op+" aborted: deadline exceeded", kv...)
+		return
+	}
+	logger.Debugw(op+" aborted: canceled", kv...)
+}
+
+// ContextWithFields 在context中附加日志字段, 已有的字段会被保留
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	prev, _ := ctx.Value(ctxFieldsKey{}).([]interface{})
+	fields := make([]interface{}, 0, len(prev)+len(keysAndValues))
+	fields = append(append(fields, prev...), keysAndValues...)
+	return context.WithValue(ctx, ctxFieldsKey{}, fields)
+}
+
+// ContextFields 获取context中通过ContextWithFields附加的日志字段和RegisterContextKeys注册的key对应的值
+// 用于中间件等在其他logger上附加相同的字段
+func ContextFields(ctx context.Context) []interface{} {
+	return contextFields(ctx)
+}
+
+// contextFields 获取context中附加的日志字段和已注册key对应的值
+func contextFields(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]interface{})
+
+	ctxKeysMu.RLock()
+	defer ctxKeysMu.RUnlock()
+	if len(ctxKeys) == 0 {
+		return fields
+	}
+	fields = append([]interface{}(nil), fields...)
+	for _, key := range ctxKeys {
+		val := ctx.Value(key)
+		if val == nil {
+			continue
+		}
+		str, ok := contextValueString(val)
+		if !ok {
+			if !getConf().DumpContextValues {
+				continue
+			}
+			str = sdump(val)
+		}
+		fields = append(fields, fmt.Sprint(key), str)
+	}
+	return fields
+}
+
+func contextValueString(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	case error:
+		return v.Error(), true
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
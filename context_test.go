@@ -0,0 +1,219 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ctxTestKey string
+
+type ctxTestValue struct{ n int }
+
+// registerTestContextKeys 注册context key, 测试结束后恢复
+func registerTestContextKeys(t *testing.T, keys ...interface{}) {
+	t.Helper()
+	ctxKeysMu.RLock()
+	prev := append([]interface{}(nil), ctxKeys...)
+	ctxKeysMu.RUnlock()
+	t.Cleanup(func() {
+		ctxKeysMu.Lock()
+		ctxKeys = prev
+		ctxKeysMu.Unlock()
+	})
+	RegisterContextKeys(keys...)
+}
+
+func TestRegisterContextKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		dump   bool
+		ctx    context.Context
+		want   map[string]interface{}
+		absent []string
+	}{
+		{"string", false, context.WithValue(context.Background(), ctxTestKey("request_id"), "r-1"), map[string]interface{}{"request_id": "r-1"}, nil},
+		{"error", false, context.WithValue(context.Background(), ctxTestKey("request_id"), errors.New("boom")), map[string]interface{}{"request_id": "boom"}, nil},
+		{"int", false, context.WithValue(context.Background(), ctxTestKey("request_id"), 7), map[string]interface{}{"request_id": "7"}, nil},
+		{"missing key", false, context.Background(), nil, []string{"request_id"}},
+		{"struct skipped", false, context.WithValue(context.Background(), ctxTestKey("request_id"), ctxTestValue{1}), nil, []string{"request_id"}},
+		{"struct dumped", true, context.WithValue(context.Background(), ctxTestKey("request_id"), ctxTestValue{1}), nil, nil},
+		{"with ContextWithFields", false, ContextWithFields(context.WithValue(context.Background(), ctxTestKey("request_id"), "r-1"), "user", "u-1"),
+			map[string]interface{}{"request_id": "r-1", "user": "u-1"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "info", DumpContextValues: tt.dump})
+			core, recorded := observer.New(TraceLevel)
+			t.Cleanup(AddCore(core))
+			registerTestContextKeys(t, ctxTestKey("request_id"))
+
+			InfoCtx(tt.ctx, "ctx line", "k", "v")
+
+			entries := recorded.FilterMessage("ctx line").All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["k"] != "v" {
+				t.Errorf("k = %v, want v", fields["k"])
+			}
+			for k, want := range tt.want {
+				if got := fields[k]; got != want {
+					t.Errorf("%s = %v, want %v", k, got, want)
+				}
+			}
+			for _, k := range tt.absent {
+				if got, ok := fields[k]; ok {
+					t.Errorf("%s = %v, want it omitted", k, got)
+				}
+			}
+			if tt.dump {
+				if got, _ := fields["request_id"].(string); !strings.Contains(got, "n: (int) 1") {
+					t.Errorf("request_id = %q, want the dumped struct", got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored bool
+		ctx    func(logger *zap.SugaredLogger) context.Context
+	}{
+		{"round trip", true, func(logger *zap.SugaredLogger) context.Context {
+			return NewContext(context.Background(), logger)
+		}},
+		{"round trip through child context", true, func(logger *zap.SugaredLogger) context.Context {
+			ctx, cancel := context.WithCancel(NewContext(context.Background(), logger))
+			t.Cleanup(cancel)
+			return ctx
+		}},
+		{"no logger", false, func(*zap.SugaredLogger) context.Context { return context.Background() }},
+		{"nil logger", false, func(*zap.SugaredLogger) context.Context {
+			return NewContext(context.Background(), nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordedDefault := observeDefault(t, "info")
+			core, recorded := observer.New(TraceLevel)
+			logger := zap.New(core).Sugar().With("stored", true)
+			registerTestContextKeys(t, ctxTestKey("request_id"))
+			ctx := context.WithValue(tt.ctx(logger), ctxTestKey("request_id"), "r-1")
+
+			if got := FromContext(ctx) == logger; got != tt.stored {
+				t.Errorf("FromContext returned the stored logger = %v, want %v", got, tt.stored)
+			}
+			FromContext(ctx).Info("from ctx")
+			WithContext(ctx).Info("with ctx")
+
+			// 保存了logger时只写入该logger, 否则写入全局logger
+			want, other := recorded, recordedDefault
+			if !tt.stored {
+				want, other = recordedDefault, recorded
+			}
+			if got := want.FilterMessage("from ctx").Len(); got != 1 {
+				t.Errorf("from ctx entries = %d, want 1", got)
+			}
+			if got := other.FilterMessage("from ctx").Len() + other.FilterMessage("with ctx").Len(); got != 0 {
+				t.Errorf("entries in the other logger = %d, want 0", got)
+			}
+			entries := want.FilterMessage("with ctx").All()
+			if len(entries) != 1 {
+				t.Fatalf("with ctx entries = %d, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["request_id"] != "r-1" {
+				t.Errorf("request_id = %v, want r-1", fields["request_id"])
+			}
+			if _, ok := fields["stored"]; ok != tt.stored {
+				t.Errorf("stored field present = %v, want %v", ok, tt.stored)
+			}
+		})
+	}
+}
+
+func TestLogContextDone(t *testing.T) {
+	errCause := errors.New("client went away")
+	tests := []struct {
+		name     string
+		ctx      func() context.Context
+		want     zapcore.Level
+		wantMsg  string
+		fields   []string
+		absent   []string
+		minElaps time.Duration
+	}{
+		{"not done", func() context.Context { return ContextWithStart(context.Background()) }, 0, "", nil, nil, 0},
+		{"canceled", func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, zapcore.DebugLevel, "fetch aborted: canceled", []string{"op", "reason"}, []string{"cause", "elapsed", "deadline"}, 0},
+		{"canceled with cause", func() context.Context {
+			ctx, cancel := context.WithCancelCause(ContextWithStart(context.Background()))
+			time.Sleep(10 * time.Millisecond)
+			cancel(errCause)
+			return ctx
+		}, zapcore.DebugLevel, "fetch aborted: canceled", []string{"op", "reason", "cause", "elapsed"}, []string{"deadline"}, 10 * time.Millisecond},
+		{"deadline exceeded", func() context.Context {
+			ctx, cancel := context.WithTimeout(ContextWithStart(context.Background()), 10*time.Millisecond)
+			t.Cleanup(cancel)
+			<-ctx.Done()
+			return ctx
+		}, zapcore.WarnLevel, "fetch aborted: deadline exceeded", []string{"op", "reason", "elapsed", "deadline", "overdue"}, []string{"cause"}, 10 * time.Millisecond},
+		{"deadline with request start", func() context.Context {
+			ctx, _ := StartRequest(ContextWithFields(context.Background(), "user", "u-1"), "fetch")
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			t.Cleanup(cancel)
+			<-ctx.Done()
+			return ctx
+		}, zapcore.WarnLevel, "fetch aborted: deadline exceeded", []string{"user", "elapsed", "deadline"}, nil, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			LogContextDone(tt.ctx(), "fetch")
+
+			entries := recorded.FilterMessageSnippet("fetch aborted").All()
+			if tt.wantMsg == "" {
+				if len(entries) != 0 {
+					t.Errorf("entries = %v, want none", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.Message != tt.wantMsg {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.Message, tt.want, tt.wantMsg)
+			}
+			fields := e.ContextMap()
+			for _, k := range tt.fields {
+				if _, ok := fields[k]; !ok {
+					t.Errorf("fields = %v, want %s", fields, k)
+				}
+			}
+			for _, k := range tt.absent {
+				if _, ok := fields[k]; ok {
+					t.Errorf("fields = %v, want no %s", fields, k)
+				}
+			}
+			if cause, ok := fields["cause"]; ok && cause != errCause.Error() {
+				t.Errorf("cause = %v, want %v", cause, errCause)
+			}
+			if elapsed, ok := fields["elapsed"].(time.Duration); ok && elapsed < tt.minElaps {
+				t.Errorf("elapsed = %s, want at least %s", elapsed, tt.minElaps)
+			}
+		})
+	}
+}
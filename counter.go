@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	errorCountsMu sync.RWMutex
+	errorCounts   = map[string]*int64{}
+)
+
+// CountError 以error级别输出日志, 并将name对应的错误计数加1
+func CountError(name, msg string, keysAndValues ...interface{}) {
+	atomic.AddInt64(errorCount(name), 1)
+	l().With("error_name", name).Errorw(msg, keysAndValues...)
+}
+
+// ErrorCounts 获取CountError记录的各类错误次数
+func ErrorCounts() map[string]int64 {
+	errorCountsMu.RLock()
+	defer errorCountsMu.RUnlock()
+
+	counts := make(map[string]int64, len(errorCounts))
+	for name, n := range errorCounts {
+		counts[name] = atomic.LoadInt64(n)
+	}
+	return counts
+}
+
+func errorCount(name string) *int64 {
+	errorCountsMu.RLock()
+	n, ok := errorCounts[name]
+	errorCountsMu.RUnlock()
+	if ok {
+		return n
+	}
+
+	errorCountsMu.Lock()
+	defer errorCountsMu.Unlock()
+	if n, ok = errorCounts[name]; !ok {
+		n = new(int64)
+		errorCounts[name] = n
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCountError(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls []string
+		want  map[string]int64
+	}{
+		{"single", []string{"count.single"}, map[string]int64{"count.single": 1}},
+		{"repeated", []string{"count.repeated", "count.repeated", "count.repeated"}, map[string]int64{"count.repeated": 3}},
+		{"separate names", []string{"count.db", "count.cache", "count.db"}, map[string]int64{"count.db": 2, "count.cache": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "error")
+			// 计数不会清零, 比较调用前后的差值
+			before := ErrorCounts()
+			for _, name := range tt.calls {
+				CountError(name, "count line", "k", "v")
+			}
+
+			counts := ErrorCounts()
+			for name, want := range tt.want {
+				if got := counts[name] - before[name]; got != want {
+					t.Errorf("ErrorCounts()[%s] = %d, want %d", name, got, want)
+				}
+			}
+			entries := recorded.FilterMessage("count line").All()
+			if len(entries) != len(tt.calls) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.calls))
+			}
+			for i, e := range entries {
+				fields := e.ContextMap()
+				if e.Level != zapcore.ErrorLevel || fields["error_name"] != tt.calls[i] || fields["k"] != "v" {
+					t.Errorf("entry = %s %v, want error with error_name %s", e.Level, fields, tt.calls[i])
+				}
+				if !strings.HasSuffix(e.Caller.File, "counter_test.go") {
+					t.Errorf("caller = %s, want counter_test.go", e.Caller.File)
+				}
+			}
+		})
+	}
+}
+
+func TestCountErrorFiltered(t *testing.T) {
+	// 日志被级别过滤时依然计数
+	recorded := observeDefault(t, "fatal")
+	before := ErrorCounts()["count.filtered"]
+	CountError("count.filtered", "count line")
+	if got := ErrorCounts()["count.filtered"] - before; got != 1 {
+		t.Errorf("ErrorCounts()[count.filtered] = %d, want 1", got)
+	}
+	if got := recorded.FilterMessage("count line").Len(); got != 0 {
+		t.Errorf("entries = %d, want 0", got)
+	}
+}
+
+func TestCountErrorConcurrent(t *testing.T) {
+	observeDefault(t, "error")
+	before := ErrorCounts()["count.concurrent"]
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			CountError("count.concurrent", "count line")
+			_ = ErrorCounts()
+		}(i)
+	}
+	wg.Wait()
+	if got := ErrorCounts()["count.concurrent"] - before; got != n {
+		t.Errorf("ErrorCounts()[count.concurrent] = %d, want %d", got, n)
+	}
+}
+
+func TestErrorCountsReturnsCopy(t *testing.T) {
+	observeDefault(t, "error")
+	CountError("count.copy", "count line")
+	counts := ErrorCounts()
+	want := counts["count.copy"]
+	counts["count.copy"] = -1
+	if got := ErrorCounts()["count.copy"]; got != want {
+		t.Errorf("ErrorCounts()[count.copy] = %d after modifying the copy, want %d", got, want)
+	}
+}
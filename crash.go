@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var crashReportMu sync.Mutex
+
+// panicReport 写入崩溃报告文件的一条记录
+type panicReport struct {
+	Timestamp  string            `json:"timestamp"`
+	Message    string            `json:"message"`
+	Stack      string            `json:"stack"`
+	Extras     []string          `json:"extras"`
+	Build      map[string]string `json:"build"`
+	InstanceID string            `json:"instance_id"`
+}
+
+// WritePanicReport 将panic以一行json写入日志目录下的 FileName_crash_report.json, 用于崩溃收集工具处理
+// 记录包含时间、panic信息、当前goroutine的调用栈、extras、构建信息和实例id(主机名-进程id)
+// 写入失败时输出到stderr
+//
+//	defer func() {
+//		if x := recover(); x != nil {
+//			logs.WritePanicReport(x, req)
+//		}
+//	}()
+func WritePanicReport(recovered interface{}, extras ...interface{}) {
+	report := panicReport{
+		Timestamp:  time.Now().Format(time.RFC3339Nano),
+		Message:    fmt.Sprint(recovered),
+		Stack:      string(debug.Stack()),
+		Extras:     make([]string, len(extras)),
+		Build:      buildInfo(),
+		InstanceID: hostname() + "-" + strconv.Itoa(os.Getpid()),
+	}
+	for i, extra := range extras {
+		report.Extras[i] = sdump(extra)
+	}
+	data, err := json.Marshal(report)
+	if err == nil {
+		c := getConf()
+		err = appendCrashReport(filepath.Join(c.Dir, c.FileName+"_crash_report.json"), append(data, '\n'))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logs: write panic report: %v\n", err)
+	}
+}
+
+func appendCrashReport(name string, data []byte) error {
+	crashReportMu.Lock()
+	defer crashReportMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func buildInfo() map[string]string {
+	info := map[string]string{"go_version": runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info["path"] = bi.Path
+		info["module"] = bi.Main.Path
+		info["version"] = bi.Main.Version
+	}
+	return info
+}
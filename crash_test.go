@@ -0,0 +1,82 @@
+package logs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePanicReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		recovered interface{}
+		extras    []interface{}
+		wantMsg   string
+		wantExtra []string
+	}{
+		{"string", "boom", nil, "boom", nil},
+		{"error extras", os.ErrNotExist, []interface{}{"req", 42}, os.ErrNotExist.Error(), []string{`(string) (len=3) "req"`, "(int) 42"}},
+		{"nil", nil, nil, "<nil>", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{})
+			before := time.Now()
+			WritePanicReport(tt.recovered, tt.extras...)
+			WritePanicReport("second")
+
+			data := readLog(t, filepath.Join(dir, "test_crash_report.json"))
+			lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("report lines = %d, want 2 appended\n%s", len(lines), data)
+			}
+			var report panicReport
+			if err := json.Unmarshal([]byte(lines[0]), &report); err != nil {
+				t.Fatalf("report = %s: %v", lines[0], err)
+			}
+			if report.Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", report.Message, tt.wantMsg)
+			}
+			ts, err := time.Parse(time.RFC3339Nano, report.Timestamp)
+			if err != nil || ts.Before(before.Truncate(time.Second)) {
+				t.Errorf("timestamp = %s (%v), want a time after %s", report.Timestamp, err, before)
+			}
+			if !strings.Contains(report.Stack, "TestWritePanicReport") {
+				t.Errorf("stack = %s, want the caller", report.Stack)
+			}
+			if len(report.Extras) != len(tt.wantExtra) {
+				t.Fatalf("extras = %q, want %d", report.Extras, len(tt.wantExtra))
+			}
+			for i, want := range tt.wantExtra {
+				if !strings.Contains(report.Extras[i], want) {
+					t.Errorf("extras[%d] = %q, want %q", i, report.Extras[i], want)
+				}
+			}
+			if got := report.Build["go_version"]; got != runtime.Version() {
+				t.Errorf("go_version = %q, want %q", got, runtime.Version())
+			}
+			if want := "-" + strconv.Itoa(os.Getpid()); !strings.HasSuffix(report.InstanceID, want) {
+				t.Errorf("instance_id = %q, want suffix %q", report.InstanceID, want)
+			}
+		})
+	}
+}
+
+func TestWritePanicReportError(t *testing.T) {
+	// 日志目录的上级是普通文件, 无法创建报告文件
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr := initConsoleLogger(t, &LogConfig{Dir: filepath.Join(file, "logs"), DisableFile: true})
+	WritePanicReport("boom")
+
+	if got := stderr(); !strings.Contains(got, "logs: write panic report:") {
+		t.Errorf("stderr = %q, want the write error", got)
+	}
+}
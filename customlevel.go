@@ -0,0 +1,104 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"sync"
+)
+
+// customLevelKey 携带自定义级别的字段名, 字段类型为SkipType, 不会被编码输出
+const customLevelKey = "\x00level"
+
+// minCustomLevel 自定义级别使用的起始级别值, 避开zap的标准级别
+const minCustomLevel = zapcore.Level(16)
+
+type customLevel struct {
+	label string
+	mapTo zapcore.Level
+}
+
+var (
+	customLevelsMu sync.RWMutex
+	// customLevels 自定义级别与级别值的对应关系
+	customLevels = map[customLevel]zapcore.Level{}
+	// customLevelLabels 级别值对应的自定义级别名
+	customLevelLabels = map[zapcore.Level]string{}
+)
+
+// Log 以自定义的级别名输出日志, 如AUDIT SECURITY等, 级别过滤和输出路由按mapTo对应的标准级别处理
+// mapTo无效时按info处理
+//
+//	logs.Log("AUDIT", "warn", "user deleted", "uid", uid)
+func Log(levelName string, mapTo string, msg string, kv ...interface{}) {
+	lvl, field := customLevelField(levelName, mapTo)
+	l().Logw(lvl, msg, append([]interface{}{field}, kv...)...)
+}
+
+// customLevelField 返回mapTo对应的标准级别, 以及携带自定义级别的字段
+func customLevelField(levelName, mapTo string) (zapcore.Level, Field) {
+	lvl, err := parseLevel(mapTo)
+	if err != nil {
+		lvl = zapcore.InfoLevel
+	}
+	key := customLevel{label: levelName, mapTo: lvl}
+
+	customLevelsMu.RLock()
+	code, ok := customLevels[key]
+	customLevelsMu.RUnlock()
+	if !ok {
+		customLevelsMu.Lock()
+		if code, ok = customLevels[key]; !ok {
+			// 级别值用完后不再区分自定义级别, 按标准级别输出
+			code = lvl
+			if n := len(customLevels); n <= int(zapcore.Level(127)-minCustomLevel) {
+				code = minCustomLevel + zapcore.Level(n)
+				customLevelLabels[code] = levelName
+			}
+			customLevels[key] = code
+		}
+		customLevelsMu.Unlock()
+	}
+	return lvl, zap.Field{Key: customLevelKey, Type: zapcore.SkipType, Integer: int64(code)}
+}
+
+func customLevelLabel(lvl zapcore.Level) (string, bool) {
+	if lvl < minCustomLevel {
+		return "", false
+	}
+	customLevelsMu.RLock()
+	defer customLevelsMu.RUnlock()
+	label, ok := customLevelLabels[lvl]
+	return label, ok
+}
+
+// customLevelEncoder 自定义级别输出级别名, 其他级别使用enc
+func customLevelEncoder(enc zapcore.LevelEncoder) zapcore.LevelEncoder {
+	return func(lvl zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		if label, ok := customLevelLabel(lvl); ok {
+			pae.AppendString(label)
+			return
+		}
+		enc(lvl, pae)
+	}
+}
+
+// customLevelEncoding 编码时将日志级别替换为字段中携带的自定义级别, 由customLevelEncoder输出级别名
+// 只在编码时替换, core按标准级别过滤和Sync, 自定义级别不会被当作高于fatal的级别
+type customLevelEncoding struct {
+	zapcore.Encoder
+}
+
+func (e customLevelEncoding) Clone() zapcore.Encoder {
+	return customLevelEncoding{e.Encoder.Clone()}
+}
+
+func (e customLevelEncoding) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	for _, f := range fields {
+		if f.Key == customLevelKey && f.Type == zapcore.SkipType {
+			ent.Level = zapcore.Level(f.Integer)
+			break
+		}
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
@@ -0,0 +1,65 @@
+package logs
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncCounter 记录写入内容和Sync次数的writer
+type syncCounter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	syncs int
+}
+
+func (w *syncCounter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *syncCounter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncs++
+	return nil
+}
+
+func TestCustomLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		label     string
+		mapTo     string
+		wantErr   bool
+		wantSyncs int
+	}{
+		{"info", "AUDIT", "info", false, 0},
+		{"error routed to error file", "SECURITY", "error", true, 0},
+		{"invalid mapTo as info", "NOTICE", "loud", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &syncCounter{}
+			dir := initTestLogger(t, &LogConfig{Level: "info", Writers: []io.Writer{w}, StacktraceLevel: "disabled"})
+			Log(tt.label, tt.mapTo, "custom entry", "k", "v")
+
+			w.mu.Lock()
+			out, syncs := w.buf.String(), w.syncs
+			w.mu.Unlock()
+			if !strings.Contains(out, tt.label) || !strings.Contains(out, "custom entry") {
+				t.Errorf("output = %q, want label %s", out, tt.label)
+			}
+			if syncs != tt.wantSyncs {
+				t.Errorf("Sync calls = %d, want %d", syncs, tt.wantSyncs)
+			}
+			_ = Sync()
+			if got := strings.Contains(readLog(t, filepath.Join(dir, "test_err.log")), tt.label); got != tt.wantErr {
+				t.Errorf("in error file = %v, want %v", got, tt.wantErr)
+			}
+		})
+	}
+}
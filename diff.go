@@ -0,0 +1,71 @@
+package logs
+
+import (
+	"io"
+	"reflect"
+)
+
+// configDiff 比较两份配置, 返回发生变化的字段, 值为包含old和new的map
+// Writers、EncoderConfigFn等包含函数或接口的字段无法有意义地比较和输出, 不参与比较
+func configDiff(old, new *LogConfig) []interface{} {
+	var kv []interface{}
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	rt := ov.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" || !diffable(f.Type) {
+			continue
+		}
+		o, n := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		kv = append(kv, f.Name, map[string]interface{}{"old": o, "new": n})
+	}
+	return kv
+}
+
+// diffable 类型t的值是否只包含可以比较和输出的数据, 不包含函数、接口和channel
+func diffable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Interface, reflect.Chan, reflect.UnsafePointer:
+		return false
+	case reflect.Map:
+		return diffable(t.Key()) && diffable(t.Elem())
+	case reflect.Slice, reflect.Array, reflect.Ptr:
+		return diffable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !diffable(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copyConfig 复制配置, 切片和map字段不与原配置共用
+func copyConfig(conf *LogConfig) *LogConfig {
+	c := *conf
+	if conf.SplitErrorFile != nil {
+		c.SplitErrorFile = BoolPtr(*conf.SplitErrorFile)
+	}
+	if conf.EnableCaller != nil {
+		c.EnableCaller = BoolPtr(*conf.EnableCaller)
+	}
+	c.MirrorDirs = append([]string(nil), conf.MirrorDirs...)
+	c.Writers = append([]io.Writer(nil), conf.Writers...)
+	if conf.Levels != nil {
+		c.Levels = make(map[string]string, len(conf.Levels))
+		for name, level := range conf.Levels {
+			c.Levels[name] = level
+		}
+	}
+	if conf.InitialFields != nil {
+		c.InitialFields = make(map[string]interface{}, len(conf.InitialFields))
+		for k, v := range conf.InitialFields {
+			c.InitialFields[k] = v
+		}
+	}
+	return &c
+}
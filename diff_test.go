@@ -0,0 +1,63 @@
+package logs
+
+import (
+	"bytes"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new LogConfig
+		want     []string
+	}{
+		{"unchanged", LogConfig{Level: "info"}, LogConfig{Level: "info"}, nil},
+		{"changed fields", LogConfig{Level: "info", MaxAge: 1}, LogConfig{Level: "debug", MaxAge: 2}, []string{"Level", "MaxAge"}},
+		{"maps and slices", LogConfig{Levels: map[string]string{"db": "warn"}}, LogConfig{Levels: map[string]string{"db": "debug"}, MirrorDirs: []string{"/tmp"}}, []string{"Levels", "MirrorDirs"}},
+		{"writers ignored", LogConfig{Writers: []io.Writer{&bytes.Buffer{}}}, LogConfig{Writers: []io.Writer{&bytes.Buffer{}, &bytes.Buffer{}}}, nil},
+		{"initial fields ignored", LogConfig{InitialFields: map[string]interface{}{"a": 1}}, LogConfig{InitialFields: map[string]interface{}{"a": 2}}, nil},
+		{"encoder config ignored", LogConfig{}, LogConfig{EncoderConfigFn: func(console, file *zapcore.EncoderConfig) {}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := configDiff(&tt.old, &tt.new)
+			var got []string
+			for i := 0; i < len(diff); i += 2 {
+				got = append(got, diff[i].(string))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("changed fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigChangedLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		implicit bool
+		want     bool
+	}{
+		{"after implicit default", true, false},
+		{"after InitLogSetting", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// 之前的配置写入另一个文件, 只检查重新初始化后的日志
+			prev := &LogConfig{Dir: dir, FileName: "prev", Level: "debug", DisableConsole: true}
+			if err := initLogSetting(prev, tt.implicit); err != nil {
+				t.Fatal(err)
+			}
+			initTestLogger(t, &LogConfig{Dir: dir, Level: "info"})
+			_ = Sync()
+			if got := strings.Contains(readLog(t, filepath.Join(dir, "test.log")), "logging config changed"); got != tt.want {
+				t.Errorf("config changed logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package logs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDingTalkQueueSize = 100
+	defaultDingTalkMaxFields = 5
+	// dingTalkAttempts 每条消息的最多发送次数, 失败后按dingTalkBackoff倍增等待
+	dingTalkAttempts     = 3
+	dingTalkFlushTimeout = 5 * time.Second
+)
+
+var (
+	dingTalkMu sync.Mutex
+	// dingTalkSink 当前的钉钉告警输出, 未启用时为nil
+	dingTalkSink *AsyncCore
+	// dingTalkBackoff 第一次重试前的等待时间
+	dingTalkBackoff = 500 * time.Millisecond
+)
+
+// DingTalkOption EnableDingTalk使用的配置项
+type DingTalkOption func(*dingTalkConfig)
+
+type dingTalkConfig struct {
+	secret    string
+	level     string
+	limit     int
+	per       time.Duration
+	maxFields int
+	wecom     bool
+	client    *http.Client
+}
+
+// DingTalkSecret 机器人安全设置中的加签密钥, 设置后请求附加timestamp和sign参数
+func DingTalkSecret(secret string) DingTalkOption {
+	return func(c *dingTalkConfig) { c.secret = secret }
+}
+
+// DingTalkLevel 发送告警的最低日志级别, 默认为error
+func DingTalkLevel(level string) DingTalkOption {
+	return func(c *dingTalkConfig) { c.level = level }
+}
+
+// DingTalkRateLimit 每per时间内最多发送n条告警, 超出的告警不发送, 到期后发送一条汇总被忽略条数的消息
+// 默认每分钟20条, n为0时不限制
+func DingTalkRateLimit(n int, per time.Duration) DingTalkOption {
+	return func(c *dingTalkConfig) { c.limit, c.per = n, per }
+}
+
+// DingTalkMaxFields 消息中最多包含的日志字段个数, 默认5
+func DingTalkMaxFields(n int) DingTalkOption {
+	return func(c *dingTalkConfig) { c.maxFields = n }
+}
+
+// DingTalkWeCom 使用企业微信群机器人的消息格式, 企业微信机器人不支持加签
+func DingTalkWeCom() DingTalkOption {
+	return func(c *dingTalkConfig) { c.wecom = true }
+}
+
+// DingTalkHTTPClient 发送告警使用的http.Client, 默认为超时5秒的http.Client
+func DingTalkHTTPClient(client *http.Client) DingTalkOption {
+	return func(c *dingTalkConfig) { c.client = client }
+}
+
+// EnableDingTalk 将默认logger中error及以上级别的日志通过钉钉群机器人发送markdown告警, 重复调用时替换之前的配置
+// 消息包含时间、级别、日志内容和前几个日志字段, 在后台goroutine发送, 失败时重试, 不会阻塞日志输出
+// 超出限流的告警按级别和日志内容分别计数, 限流周期结束后发送一条汇总消息
+// 最终发送失败时不输出日志, 只计入DingTalkFailures, 不再使用时调用DisableDingTalk
+//
+//	err := logs.EnableDingTalk(webhook, logs.DingTalkSecret(secret), logs.DingTalkRateLimit(10, time.Minute))
+func EnableDingTalk(webhook string, opts ...DingTalkOption) error {
+	c := &dingTalkConfig{
+		level:     "error",
+		limit:     20,
+		per:       time.Minute,
+		maxFields: defaultDingTalkMaxFields,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	lvl, err := parseLevel(c.level)
+	if err != nil {
+		return fmt.Errorf("logs: invalid dingtalk level %q, accepted values: %s", c.level, levelNames)
+	}
+	if _, err := url.Parse(webhook); err != nil || webhook == "" {
+		return fmt.Errorf("logs: invalid dingtalk webhook %q", webhook)
+	}
+	if c.limit < 0 || (c.limit > 0 && c.per <= 0) {
+		return fmt.Errorf("logs: invalid dingtalk rate limit %d per %v", c.limit, c.per)
+	}
+
+	d := &dingTalk{webhook: webhook, conf: c, now: time.Now}
+	ac := AsyncCoreConfig{
+		Name:         "dingtalk",
+		Level:        lvl,
+		QueueSize:    defaultDingTalkQueueSize,
+		FlushTimeout: dingTalkFlushTimeout,
+		Encode: func(ent zapcore.Entry, fields []zapcore.Field) (interface{}, error) {
+			return d.message(ent, fields), nil
+		},
+		Send: func(msg interface{}, done <-chan struct{}) error {
+			return d.deliver(msg.(dingTalkMessage), done)
+		},
+	}
+	if c.limit > 0 {
+		// 限流周期结束时没有新的告警也要发送汇总
+		ac.Tick = d.summarize
+		ac.Interval = c.per
+		if ac.Interval > time.Second {
+			ac.Interval = time.Second
+		}
+	}
+	sink, err := AddAsyncCore(ac)
+	if err != nil {
+		return err
+	}
+
+	dingTalkMu.Lock()
+	prev := dingTalkSink
+	dingTalkSink = sink
+	dingTalkMu.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// DisableDingTalk 停止发送钉钉告警, 等待队列中的消息发送完成或超时, 未启用时不做任何事
+func DisableDingTalk() error {
+	dingTalkMu.Lock()
+	prev := dingTalkSink
+	dingTalkSink = nil
+	dingTalkMu.Unlock()
+	if prev == nil {
+		return nil
+	}
+	return prev.Close()
+}
+
+// DingTalkFailures 当前的钉钉告警发送失败和队列已满丢弃的消息条数
+func DingTalkFailures() uint64 {
+	dingTalkMu.Lock()
+	defer dingTalkMu.Unlock()
+	if dingTalkSink == nil {
+		return 0
+	}
+	return dingTalkSink.Failures()
+}
+
+// dingTalkMessage 一条markdown告警, key为级别和日志内容, 用于统计被限流的相同告警
+type dingTalkMessage struct {
+	key   string
+	title string
+	text  string
+}
+
+// dingTalk 钉钉告警的发送方, 只在AsyncCore的后台goroutine中调用, 限流状态无需加锁
+type dingTalk struct {
+	webhook string
+	conf    *dingTalkConfig
+	// now 获取当前时间, 默认time.Now
+	now func() time.Time
+
+	windowStart time.Time
+	sent        int
+	// suppressed 当前限流周期内每种告警被忽略的条数, order为首次被忽略的顺序
+	suppressed map[string]int
+	order      []string
+}
+
+// message 日志对应的markdown告警, 包含时间、级别、日志内容和前几个日志字段
+func (d *dingTalk) message(ent zapcore.Entry, fields []zapcore.Field) dingTalkMessage {
+	title := "[" + strings.ToUpper(levelString(ent.Level)) + "] " + ent.Message
+	var sb strings.Builder
+	sb.WriteString("### " + title + "\n\n")
+	sb.WriteString("- time: " + ent.Time.Format("2006-01-02 15:04:05.000") + "\n")
+	sb.WriteString("- level: " + levelString(ent.Level) + "\n")
+	if ent.LoggerName != "" {
+		sb.WriteString("- logger: " + ent.LoggerName + "\n")
+	}
+	if ent.Caller.Defined {
+		sb.WriteString("- caller: " + ent.Caller.TrimmedPath() + "\n")
+	}
+	n := 0
+	for _, f := range fields {
+		if n >= d.conf.maxFields {
+			break
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			fmt.Fprintf(&sb, "- %s: %v\n", k, v)
+		}
+		n++
+	}
+	return dingTalkMessage{key: title, title: title, text: sb.String()}
+}
+
+// deliver 按限流发送一条告警, 超出限流时只计数
+func (d *dingTalk) deliver(msg dingTalkMessage, done <-chan struct{}) error {
+	if d.conf.limit == 0 {
+		return d.post(msg.title, msg.text, done)
+	}
+	d.summarize(done)
+	if d.sent >= d.conf.limit {
+		if d.suppressed == nil {
+			d.suppressed = map[string]int{}
+		}
+		if d.suppressed[msg.key] == 0 {
+			d.order = append(d.order, msg.key)
+		}
+		d.suppressed[msg.key]++
+		return nil
+	}
+	d.sent++
+	return d.post(msg.title, msg.text, done)
+}
+
+// summarize 限流周期结束时发送每种告警被忽略的条数, 并开始新的限流周期
+func (d *dingTalk) summarize(done <-chan struct{}) {
+	if d.now().Sub(d.windowStart) < d.conf.per {
+		return
+	}
+	d.windowStart, d.sent = d.now(), 0
+	if len(d.order) == 0 {
+		return
+	}
+	title := fmt.Sprintf("suppressed %d kinds of similar alerts", len(d.order))
+	var sb strings.Builder
+	sb.WriteString("### " + title + "\n\n")
+	sb.WriteString("- time: " + d.windowStart.Format("2006-01-02 15:04:05.000") + "\n")
+	for _, key := range d.order {
+		fmt.Fprintf(&sb, "- suppressed %d similar: %s\n", d.suppressed[key], key)
+	}
+	d.suppressed, d.order = nil, nil
+	d.sent++
+	_ = d.post(title, sb.String(), done)
+}
+
+// post 发送一条markdown消息, 失败时重试
+func (d *dingTalk) post(title, text string, done <-chan struct{}) error {
+	var payload interface{}
+	if d.conf.wecom {
+		payload = map[string]interface{}{"msgtype": "markdown", "markdown": map[string]string{"content": text}}
+	} else {
+		payload = map[string]interface{}{"msgtype": "markdown", "markdown": map[string]string{"title": title, "text": text}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	backoff := dingTalkBackoff
+	for attempt := 1; ; attempt++ {
+		if err = d.send(body); err == nil || attempt == dingTalkAttempts {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-done:
+			return err
+		}
+	}
+}
+
+// send 发送一次请求, 返回的errcode不为0时同样视为失败
+func (d *dingTalk) send(body []byte) error {
+	target := d.webhook
+	if d.conf.secret != "" && !d.conf.wecom {
+		timestamp, sign := dingTalkSign(d.conf.secret, d.now())
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+	resp, err := d.conf.client.Post(target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return errors.New(result.ErrMsg)
+	}
+	return nil
+}
+
+// dingTalkSign 钉钉加签, 对"毫秒时间戳\n密钥"做HmacSHA256后base64编码
+func dingTalkSign(secret string, t time.Time) (timestamp, sign string) {
+	timestamp = strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return timestamp, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,206 @@
+package logs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer 记录请求内容的测试服务器, status依次返回, 用完后返回200
+type webhookServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []string
+	urls   []string
+	status []int
+}
+
+func newWebhookServer(t *testing.T, response string, status ...int) *webhookServer {
+	t.Helper()
+	s := &webhookServer{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.urls = append(s.urls, r.URL.String())
+		code := http.StatusOK
+		if len(s.status) > 0 {
+			code, s.status = s.status[0], s.status[1:]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+// fastRetries 缩短测试中的重试和熔断等待时间
+func fastRetries(t *testing.T) {
+	t.Helper()
+	slackBackoffs, cooldown, dingTalkBackoffs := slackBackoff, slackBreakerCooldown, dingTalkBackoff
+	slackBackoff, slackBreakerCooldown, dingTalkBackoff = time.Millisecond, 50*time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		slackBackoff, slackBreakerCooldown, dingTalkBackoff = slackBackoffs, cooldown, dingTalkBackoffs
+	})
+}
+
+func TestDingTalkMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []DingTalkOption
+		wantKey   string
+		wantText  []string
+		wantQuery []string
+	}{
+		{
+			name:      "dingtalk signed",
+			opts:      []DingTalkOption{DingTalkSecret("secret"), DingTalkMaxFields(3)},
+			wantKey:   "text",
+			wantText:  []string{"### [ERROR] disk full", "- level: error", "- logger: disk", "- path: /data"},
+			wantQuery: []string{"timestamp", "sign"},
+		},
+		{
+			name:     "wecom",
+			opts:     []DingTalkOption{DingTalkWeCom(), DingTalkSecret("ignored")},
+			wantKey:  "content",
+			wantText: []string{"### [ERROR] disk full", "- path: /data"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{DisableFile: true})
+			srv := newWebhookServer(t, `{"errcode":0}`)
+			if err := EnableDingTalk(srv.URL+"/robot/send?access_token=x", tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			defer DisableDingTalk()
+
+			Named("disk").Errorw("disk full", "path", "/data", "free", 0)
+			if err := Sync(); err != nil {
+				t.Fatal(err)
+			}
+			reqs := srv.requests()
+			if len(reqs) != 1 {
+				t.Fatalf("requests = %q, want 1", reqs)
+			}
+			var payload struct {
+				MsgType  string            `json:"msgtype"`
+				Markdown map[string]string `json:"markdown"`
+			}
+			if err := json.Unmarshal([]byte(reqs[0]), &payload); err != nil {
+				t.Fatal(err)
+			}
+			text := payload.Markdown[tt.wantKey]
+			for _, want := range tt.wantText {
+				if !strings.Contains(text, want) {
+					t.Errorf("markdown %s = %q, want %q", tt.wantKey, text, want)
+				}
+			}
+			if strings.Contains(text, "- free:") && strings.Contains(tt.name, "signed") {
+				t.Errorf("markdown = %q, want at most 3 fields", text)
+			}
+
+			srv.mu.Lock()
+			u, _ := url.Parse(srv.urls[0])
+			srv.mu.Unlock()
+			for _, key := range []string{"timestamp", "sign"} {
+				if got := u.Query().Get(key) != ""; got != contains(tt.wantQuery, key) {
+					t.Errorf("query %q present = %v in %s", key, got, u)
+				}
+			}
+			if u.Query().Get("access_token") != "x" {
+				t.Errorf("query = %s, want the original parameters kept", u)
+			}
+		})
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDingTalkSign(t *testing.T) {
+	// 钉钉文档中的算法: base64(HmacSHA256(timestamp+"\n"+secret)), 密钥同时作为HMAC的key
+	timestamp, sign := dingTalkSign("SEC123", time.Unix(1700000000, 0))
+	if timestamp != "1700000000000" {
+		t.Errorf("timestamp = %q", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("SEC123"))
+	mac.Write([]byte("1700000000000\nSEC123"))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); sign != want {
+		t.Errorf("sign = %q, want %q", sign, want)
+	}
+}
+
+func TestDingTalkRateLimit(t *testing.T) {
+	fastRetries(t)
+	initTestLogger(t, &LogConfig{DisableFile: true})
+	srv := newWebhookServer(t, `{"errcode":0}`)
+	if err := EnableDingTalk(srv.URL, DingTalkRateLimit(2, 200*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableDingTalk()
+
+	for _, msg := range []string{"a", "b", "db down", "db down", "db down", "cache miss"} {
+		Error(msg)
+	}
+	_ = Sync()
+	if got := len(srv.requests()); got != 2 {
+		t.Fatalf("requests within the window = %d, want 2", got)
+	}
+
+	// 限流周期结束后发送按告警分别计数的汇总
+	deadline := time.Now().Add(2 * time.Second)
+	for len(srv.requests()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	reqs := srv.requests()
+	if len(reqs) != 3 {
+		t.Fatalf("requests = %d, want the summary", len(reqs))
+	}
+	for _, want := range []string{"suppressed 2 kinds", `suppressed 3 similar: [ERROR] db down`, `suppressed 1 similar: [ERROR] cache miss`} {
+		if !strings.Contains(reqs[2], want) {
+			t.Errorf("summary = %q, want %q", reqs[2], want)
+		}
+	}
+}
+
+func TestDingTalkFailures(t *testing.T) {
+	fastRetries(t)
+	initTestLogger(t, &LogConfig{DisableFile: true})
+	srv := newWebhookServer(t, `{"errcode":310000,"errmsg":"sign not match"}`)
+	if err := EnableDingTalk(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableDingTalk()
+
+	Error("rejected")
+	_ = Sync()
+	if got := len(srv.requests()); got != dingTalkAttempts {
+		t.Errorf("requests = %d, want %d attempts", got, dingTalkAttempts)
+	}
+	if got := DingTalkFailures(); got != 1 {
+		t.Errorf("DingTalkFailures() = %d, want 1", got)
+	}
+}
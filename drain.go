@@ -0,0 +1,66 @@
+package logs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// asyncSink 异步写入的输出, Drain时等待其写完
+type asyncSink struct {
+	name string
+	sync func() error
+}
+
+// Drain 通知所有异步输出写入缓冲中的日志, 并等待写完或超时, 包括AddAsyncCore注册的输出
+// 返回的错误中包含写入失败或超时的输出名称
+func Drain(timeout time.Duration) error {
+	return std().outputs.drain(timeout)
+}
+
+// drain 等待o中的异步输出和AddAsyncCore注册的输出写完或超时
+func (o *outputs) drain(timeout time.Duration) error {
+	sinks := o.asyncSinks
+	sinks = append(sinks[:len(sinks):len(sinks)], registeredAsyncCores()...)
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	type result struct {
+		i   int
+		err error
+	}
+	results := make(chan result, len(sinks))
+	pending := make(map[int]bool, len(sinks))
+	for i, s := range sinks {
+		pending[i] = true
+		go func(i int, s asyncSink) {
+			results <- result{i: i, err: s.sync()}
+		}(i, s)
+	}
+
+	var failed []string
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+wait:
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.i)
+			if r.err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", sinks[r.i].name, r.err))
+			}
+		case <-timer.C:
+			break wait
+		}
+	}
+	for i := range pending {
+		failed = append(failed, sinks[i].name+": timed out")
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("logs: drain failed: %s", strings.Join(failed, "; "))
+}
@@ -0,0 +1,33 @@
+package logs
+
+import (
+	"github.com/davecgh/go-spew/spew"
+	"sync/atomic"
+)
+
+// defaultDumpConfig 默认的spew配置, 限制嵌套层数并且不输出指针地址, 避免输出过大
+var defaultDumpConfig = &spew.ConfigState{
+	Indent:                  " ",
+	MaxDepth:                5,
+	DisablePointerAddresses: true,
+	DisableCapacities:       true,
+}
+
+var dumpConfig atomic.Value
+
+// SetDumpConfig 设置PrintPanicStack输出extras以及DumpContextValues输出context值时使用的spew配置
+// cfg为nil时恢复默认配置
+func SetDumpConfig(cfg *spew.ConfigState) {
+	if cfg == nil {
+		cfg = defaultDumpConfig
+	}
+	dumpConfig.Store(cfg)
+}
+
+func sdump(v interface{}) string {
+	cfg, _ := dumpConfig.Load().(*spew.ConfigState)
+	if cfg == nil {
+		cfg = defaultDumpConfig
+	}
+	return cfg.Sdump(v)
+}
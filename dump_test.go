@@ -0,0 +1,74 @@
+package logs
+
+import (
+	"context"
+	"github.com/davecgh/go-spew/spew"
+	"go.uber.org/zap/zaptest/observer"
+	"strings"
+	"testing"
+)
+
+type dumpTestNode struct {
+	Name  string
+	Child *dumpTestNode
+	Tags  []string
+}
+
+func TestSetDumpConfig(t *testing.T) {
+	deep := &dumpTestNode{Name: "a", Child: &dumpTestNode{Name: "b", Child: &dumpTestNode{Name: "c"}}, Tags: make([]string, 1, 8)}
+	tests := []struct {
+		name   string
+		cfg    *spew.ConfigState
+		want   []string
+		absent []string
+	}{
+		{"default", nil, []string{`Name: (string) (len=1) "c"`, "Tags: ([]string) (len=1)"}, []string{"(0x", "cap="}},
+		{"custom", &spew.ConfigState{Indent: "\t", MaxDepth: 2}, []string{"<max depth reached>", "cap=8", "(0x"}, []string{`"c"`}},
+		{"compact", &spew.ConfigState{DisablePointerAddresses: true, DisableCapacities: true, DisableMethods: true, MaxDepth: 1}, []string{`"a"`, "<max depth reached>"}, []string{`"b"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDumpConfig(tt.cfg)
+			defer SetDumpConfig(nil)
+			got := sdump(deep)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("sdump = %s, want %s", got, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("sdump = %s, want no %s", got, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestSetDumpConfigReset(t *testing.T) {
+	SetDumpConfig(&spew.ConfigState{MaxDepth: 1})
+	SetDumpConfig(nil)
+	if got, want := sdump(&dumpTestNode{Name: "a", Child: &dumpTestNode{Name: "b"}}), defaultDumpConfig.Sdump(&dumpTestNode{Name: "a", Child: &dumpTestNode{Name: "b"}}); got != want {
+		t.Errorf("sdump after reset = %s, want the default %s", got, want)
+	}
+}
+
+func TestSetDumpConfigContextValues(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info", DumpContextValues: true})
+	core, recorded := observer.New(TraceLevel)
+	t.Cleanup(AddCore(core))
+	registerTestContextKeys(t, ctxTestKey("node"))
+	SetDumpConfig(&spew.ConfigState{MaxDepth: 1, DisablePointerAddresses: true})
+	defer SetDumpConfig(nil)
+
+	ctx := context.WithValue(context.Background(), ctxTestKey("node"), dumpTestNode{Name: "a", Child: &dumpTestNode{Name: "b"}})
+	InfoCtx(ctx, "dump line")
+
+	entries := recorded.FilterMessage("dump line").All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if got, _ := entries[0].ContextMap()["node"].(string); !strings.Contains(got, "<max depth reached>") || strings.Contains(got, `"b"`) {
+		t.Errorf("node = %s, want it dumped with the custom config", got)
+	}
+}
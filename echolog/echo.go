@@ -0,0 +1,87 @@
+// Package echolog 将echo的请求日志和panic写入默认logger的中间件
+package echolog
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// Logger 输出请求日志的echo中间件, 字段为method route status latency client_ip bytes
+// route为路由的模式而不是实际路径, 使用echo的RequestID中间件时附加request_id
+// 状态码4xx以warn级别输出, 5xx以error级别输出, 其他以info级别输出, 日志附加字段system=echo
+//
+//	e := echo.New()
+//	e.Use(middleware.RequestID(), echolog.Logger(), echolog.Recovery())
+func Logger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// 由错误处理写入响应后才能得到状态码
+				c.Error(err)
+			}
+
+			req, res := c.Request(), c.Response()
+			fields := []interface{}{
+				"method", req.Method,
+				"route", c.Path(),
+				"status", res.Status,
+				"latency", time.Since(start),
+				"client_ip", c.RealIP(),
+				"bytes", res.Size,
+			}
+			id := res.Header().Get(echo.HeaderXRequestID)
+			if id == "" {
+				id = req.Header.Get(echo.HeaderXRequestID)
+			}
+			if id != "" {
+				fields = append(fields, "request_id", id)
+			}
+			if err != nil {
+				fields = append(fields, "error", err)
+			}
+			logger := echoLogger()
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				logger.Errorw("request", fields...)
+			case res.Status >= http.StatusBadRequest:
+				logger.Warnw("request", fields...)
+			default:
+				logger.Infow("request", fields...)
+			}
+			return err
+		}
+	}
+}
+
+// Recovery 恢复handler中panic的echo中间件, 与logs.PrintPanicStack相同输出panic的值和调用栈, 附加字段request为请求行
+// 恢复后返回500错误, 由echo的错误处理写入响应
+func Recovery() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				x := recover()
+				if x == nil {
+					return
+				}
+				req := c.Request()
+				logger := echoLogger().With("request", req.Method+" "+req.URL.RequestURI()+" "+req.Proto)
+				if fields := logs.ContextFields(req.Context()); len(fields) > 0 {
+					logger = logger.With(fields...)
+				}
+				logs.LogPanic(logger, x, true)
+				err = echo.ErrInternalServerError
+			}()
+			return next(c)
+		}
+	}
+}
+
+// echoLogger 中间件使用的logger, 调用位置为echo内部, 不输出调用位置
+func echoLogger() *zap.SugaredLogger {
+	return logs.Desugar().WithOptions(zap.WithCaller(false)).Sugar().With("system", "echo")
+}
@@ -0,0 +1,114 @@
+package echolog
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// observe 使用临时目录初始化默认logger, 返回写入默认logger的日志
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+	core, recorded := observer.New(zapcore.DebugLevel)
+	t.Cleanup(logs.AddCore(core))
+	return recorded
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   echo.HandlerFunc
+		wantLevel zapcore.Level
+		wantCode  int64
+		wantError bool
+	}{
+		{"ok", func(c echo.Context) error { return c.String(http.StatusOK, "ok") }, zapcore.InfoLevel, 200, false},
+		{"not found error", func(c echo.Context) error { return echo.ErrNotFound }, zapcore.WarnLevel, 404, true},
+		{"server error", func(c echo.Context) error { return echo.NewHTTPError(http.StatusServiceUnavailable) }, zapcore.ErrorLevel, 503, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			e := echo.New()
+			e.Use(middleware.RequestID(), Logger())
+			e.GET("/users/:id", tt.handler)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+			entries := recorded.FilterMessage("request").All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			ent := entries[0]
+			fields := ent.ContextMap()
+			if ent.Level != tt.wantLevel || fields["status"] != tt.wantCode || fields["route"] != "/users/:id" || fields["system"] != "echo" {
+				t.Errorf("entry = %s %v, want %s status %d", ent.Level, fields, tt.wantLevel, tt.wantCode)
+			}
+			if fields["request_id"] != rec.Header().Get(echo.HeaderXRequestID) || fields["request_id"] == "" {
+				t.Errorf("request_id = %v, want the response header", fields["request_id"])
+			}
+			if _, ok := fields["error"]; ok != tt.wantError {
+				t.Errorf("error field present = %v, want %v", ok, tt.wantError)
+			}
+			if rec.Code != int(tt.wantCode) {
+				t.Errorf("response status = %d, want %d written once", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantKV string
+	}{
+		{"plain", context.Background(), "boom", ""},
+		{"context fields", logs.ContextWithFields(context.Background(), "trace_id", "t-1"), "boom", "t-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			e := echo.New()
+			e.Use(Recovery())
+			e.GET("/panic", func(c echo.Context) error { panic("boom") })
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil).WithContext(tt.ctx))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status = %d, want 500", rec.Code)
+			}
+			entries := recorded.All()
+			if len(entries) == 0 {
+				t.Fatal("no entries logged")
+			}
+			first := entries[0]
+			if first.Level != zapcore.ErrorLevel || first.Message != tt.want || first.ContextMap()["request"] != "GET /panic HTTP/1.1" {
+				t.Errorf("entry = %s %q %v", first.Level, first.Message, first.ContextMap())
+			}
+			if tt.wantKV != "" && first.ContextMap()["trace_id"] != tt.wantKV {
+				t.Errorf("trace_id = %v, want %s", first.ContextMap()["trace_id"], tt.wantKV)
+			}
+			var frames int
+			for _, ent := range entries {
+				if strings.HasPrefix(ent.Message, "frame ") {
+					frames++
+				}
+			}
+			if frames == 0 {
+				t.Error("no stack frames logged")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// envVars 可通过环境变量覆盖的配置
+var envVars = []struct {
+	name string
+	set  func(c *LogConfig, value string) error
+}{
+	{"LOGS_LEVEL", func(c *LogConfig, v string) error { c.Level = v; return nil }},
+	{"LOGS_FILENAME", func(c *LogConfig, v string) error { c.FileName = v; return nil }},
+	{"LOGS_ERROR_FILENAME", func(c *LogConfig, v string) error { c.ErrorFileName = v; return nil }},
+	{"LOGS_DIR", func(c *LogConfig, v string) error { c.Dir = v; return nil }},
+	{"LOGS_ENCODING", func(c *LogConfig, v string) error { c.Encoding = v; return nil }},
+	{"LOGS_TIMEZONE", func(c *LogConfig, v string) error { c.Timezone = v; return nil }},
+	{"LOGS_TIME_LAYOUT", func(c *LogConfig, v string) error { c.TimeLayout = v; return nil }},
+	{"LOGS_MAX_AGE", func(c *LogConfig, v string) error { return parseEnvInt(v, &c.MaxAge) }},
+	{"LOGS_MAX_SIZE", func(c *LogConfig, v string) error { return parseEnvInt(v, &c.MaxSize) }},
+	{"LOGS_MAX_BACKUPS", func(c *LogConfig, v string) error { return parseEnvInt(v, &c.MaxBackups) }},
+	{"LOGS_COMPRESS", func(c *LogConfig, v string) error { return parseEnvBool(v, &c.Compress) }},
+	{"LOGS_LOCAL_TIME", func(c *LogConfig, v string) error { return parseEnvBool(v, &c.LocalTime) }},
+	{"LOGS_DISABLE_FILE", func(c *LogConfig, v string) error { return parseEnvBool(v, &c.DisableFile) }},
+	{"LOGS_DISABLE_CONSOLE", func(c *LogConfig, v string) error { return parseEnvBool(v, &c.DisableConsole) }},
+}
+
+// LoadConfigFromEnv 在默认配置上应用环境变量, 环境变量无法解析或配置无效时返回错误
+func LoadConfigFromEnv() (*LogConfig, error) {
+	c := defaultLogConfig()
+	if err := applyEnv(c); err != nil {
+		return nil, err
+	}
+	if err := validateConfig(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// InitFromEnv 在conf上应用环境变量后初始化日志, conf不会被修改
+// 优先级: 已设置的环境变量 > conf > 默认值, 值为空的环境变量视为未设置
+//
+// 支持的环境变量: LOGS_LEVEL LOGS_FILENAME LOGS_ERROR_FILENAME LOGS_DIR LOGS_ENCODING
+// LOGS_TIMEZONE LOGS_TIME_LAYOUT LOGS_MAX_AGE LOGS_MAX_SIZE LOGS_MAX_BACKUPS
+// LOGS_COMPRESS LOGS_LOCAL_TIME LOGS_DISABLE_FILE LOGS_DISABLE_CONSOLE
+func InitFromEnv(conf *LogConfig) error {
+	c := defaultLogConfig()
+	if conf != nil {
+		c = copyConfig(conf)
+	}
+	if err := applyEnv(c); err != nil {
+		return err
+	}
+	return InitLogSetting(c)
+}
+
+func applyEnv(c *LogConfig) error {
+	for _, env := range envVars {
+		v := os.Getenv(env.name)
+		if v == "" {
+			continue
+		}
+		if err := env.set(c, v); err != nil {
+			return fmt.Errorf("logs: invalid %s %q: %w", env.name, v, err)
+		}
+	}
+	return nil
+}
+
+func parseEnvInt(v string, dst *int) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*dst = n
+	return nil
+}
+
+func parseEnvBool(v string, dst *bool) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*dst = b
+	return nil
+}
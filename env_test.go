@@ -0,0 +1,78 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(c *LogConfig) bool
+		wantErr string
+	}{
+		{"unset", nil, func(c *LogConfig) bool { return c.Level == "debug" && c.FileName == "log" && c.MaxAge == 20 }, ""},
+		{"strings", map[string]string{"LOGS_LEVEL": "warn", "LOGS_FILENAME": "api", "LOGS_DIR": "/var/log/api", "LOGS_ENCODING": "json"},
+			func(c *LogConfig) bool {
+				return c.Level == "warn" && c.FileName == "api" && c.Dir == "/var/log/api" && c.Encoding == "json"
+			}, ""},
+		{"numbers and bools", map[string]string{"LOGS_MAX_AGE": "7", "LOGS_MAX_BACKUPS": "3", "LOGS_COMPRESS": "true", "LOGS_LOCAL_TIME": "false"},
+			func(c *LogConfig) bool { return c.MaxAge == 7 && c.MaxBackups == 3 && c.Compress && !c.LocalTime }, ""},
+		{"empty is unset", map[string]string{"LOGS_LEVEL": ""}, func(c *LogConfig) bool { return c.Level == "debug" }, ""},
+		{"bad integer", map[string]string{"LOGS_MAX_AGE": "a week"}, nil, `invalid LOGS_MAX_AGE "a week"`},
+		{"bad bool", map[string]string{"LOGS_COMPRESS": "maybe"}, nil, "LOGS_COMPRESS"},
+		{"unknown level", map[string]string{"LOGS_LEVEL": "loud"}, nil, "invalid Level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range envVars {
+				t.Setenv(env.name, tt.env[env.name])
+			}
+			c, err := LoadConfigFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfigFromEnv error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(c) {
+				t.Errorf("config = %+v", c)
+			}
+		})
+	}
+}
+
+func TestInitFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantLevel string
+		wantErr   bool
+	}{
+		{"conf without env", nil, "info", false},
+		{"env takes precedence", map[string]string{"LOGS_LEVEL": "error"}, "error", false},
+		{"parse error keeps previous config", map[string]string{"LOGS_MAX_SIZE": "big"}, "debug", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "debug"})
+			for _, env := range envVars {
+				t.Setenv(env.name, tt.env[env.name])
+			}
+			conf := &LogConfig{Dir: dir, FileName: "test", Level: "info", DisableConsole: true}
+			if err := InitFromEnv(conf); (err != nil) != tt.wantErr {
+				t.Fatalf("InitFromEnv error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := GetLogConf().Level; got != tt.wantLevel {
+				t.Errorf("Level = %q, want %q", got, tt.wantLevel)
+			}
+			if conf.Level != "info" {
+				t.Errorf("conf modified: Level = %q", conf.Level)
+			}
+		})
+	}
+}
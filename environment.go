@@ -0,0 +1,52 @@
+package logs
+
+import (
+	"os"
+	"regexp"
+	"runtime"
+	"sync/atomic"
+)
+
+// defaultEnvRedactPattern 默认隐藏值的环境变量名, 如DB_PASSWORD API_TOKEN AWS_SECRET_ACCESS_KEY
+var defaultEnvRedactPattern = regexp.MustCompile(`(?i)passw|pwd|secret|token|credential|key|auth|cookie|session`)
+
+var envRedactPattern atomic.Value
+
+// SetEnvRedactPattern 设置LogEnvironment隐藏值的环境变量名规则, 名称匹配re的变量值输出为******
+// re为nil时恢复默认规则, 默认规则匹配名称中包含password secret token key等的变量
+func SetEnvRedactPattern(re *regexp.Regexp) {
+	if re == nil {
+		re = defaultEnvRedactPattern
+	}
+	envRedactPattern.Store(re)
+}
+
+func redactEnv(key string) bool {
+	re, _ := envRedactPattern.Load().(*regexp.Regexp)
+	if re == nil {
+		re = defaultEnvRedactPattern
+	}
+	return re.MatchString(key)
+}
+
+// LogEnvironment 输出一条包含运行时信息和指定环境变量的Info日志, 未设置的环境变量不输出
+// 名称匹配SetEnvRedactPattern规则的环境变量只输出******
+func LogEnvironment(keys ...string) {
+	env := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if val, ok := os.LookupEnv(key); ok {
+			if redactEnv(key) {
+				val = redactedValue
+			}
+			env[key] = val
+		}
+	}
+	l().Infow("environment",
+		"go_version", runtime.Version(),
+		"goos", runtime.GOOS,
+		"goarch", runtime.GOARCH,
+		"num_cpu", runtime.NumCPU(),
+		"gomaxprocs", runtime.GOMAXPROCS(0),
+		"env", env,
+	)
+}
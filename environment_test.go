@@ -0,0 +1,57 @@
+package logs
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"testing"
+)
+
+func TestLogEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern *regexp.Regexp
+		want    map[string]string
+	}{
+		{"default pattern", nil, map[string]string{
+			"LOGS_TEST_REGION":      "cn",
+			"LOGS_TEST_DB_PASSWORD": "******",
+			"LOGS_TEST_API_TOKEN":   "******",
+			"LOGS_TEST_SECRET_KEY":  "******",
+			"LOGS_TEST_EMPTY":       "",
+		}},
+		{"custom pattern", regexp.MustCompile(`REGION`), map[string]string{
+			"LOGS_TEST_REGION":      "******",
+			"LOGS_TEST_DB_PASSWORD": "hunter2",
+			"LOGS_TEST_API_TOKEN":   "t-123",
+			"LOGS_TEST_SECRET_KEY":  "s3cr3t",
+			"LOGS_TEST_EMPTY":       "",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "info")
+			SetEnvRedactPattern(tt.pattern)
+			defer SetEnvRedactPattern(nil)
+			t.Setenv("LOGS_TEST_REGION", "cn")
+			t.Setenv("LOGS_TEST_DB_PASSWORD", "hunter2")
+			t.Setenv("LOGS_TEST_API_TOKEN", "t-123")
+			t.Setenv("LOGS_TEST_SECRET_KEY", "s3cr3t")
+			t.Setenv("LOGS_TEST_EMPTY", "")
+
+			LogEnvironment("LOGS_TEST_REGION", "LOGS_TEST_DB_PASSWORD", "LOGS_TEST_API_TOKEN", "LOGS_TEST_SECRET_KEY", "LOGS_TEST_EMPTY", "LOGS_TEST_UNSET")
+
+			entries := recorded.FilterMessage("environment").All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if got := fields["env"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("env = %v, want %v", got, tt.want)
+			}
+			if got := fields["gomaxprocs"]; got != int64(runtime.GOMAXPROCS(0)) {
+				t.Errorf("gomaxprocs = %v, want %d", got, runtime.GOMAXPROCS(0))
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stackTracer github.com/pkg/errors创建的错误实现的接口
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// WithError 获取附加了err的logger, 字段为error error_type, err或其包装的错误带有调用栈时附加error_stack
+// err为nil时不附加字段
+//
+//	logs.WithError(err).Error("save order failed")
+func WithError(err error) *Logger {
+	return With().WithError(err)
+}
+
+// WithError 获取附加了err的logger, err为nil时返回lg
+func (lg *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return lg
+	}
+	args := []interface{}{"error", err.Error(), "error_type", fmt.Sprintf("%T", err)}
+	if stack := errorStack(err); stack != "" {
+		args = append(args, "error_stack", stack)
+	}
+	return lg.With(args...)
+}
+
+// errorStack 在err及其包装的错误中查找调用栈
+// 支持github.com/pkg/errors创建的错误, 以及%+v输出比Error()更多内容的错误
+func errorStack(err error) string {
+	for _, e := range unwrapAll(err) {
+		if st, ok := e.(stackTracer); ok {
+			if stack := fmt.Sprintf("%+v", st.StackTrace()); stack != "" {
+				return stack
+			}
+		}
+	}
+	for _, e := range unwrapAll(err) {
+		if _, ok := e.(fmt.Formatter); !ok {
+			continue
+		}
+		if s := fmt.Sprintf("%+v", e); s != e.Error() {
+			return s
+		}
+	}
+	return ""
+}
+
+// unwrapAll 按深度优先返回err及其包装的所有错误, 支持Unwrap() error和Unwrap() []error
+func unwrapAll(err error) []error {
+	var errs []error
+	var walk func(error)
+	walk = func(e error) {
+		for e != nil {
+			errs = append(errs, e)
+			if multi, ok := e.(interface{ Unwrap() []error }); ok {
+				for _, inner := range multi.Unwrap() {
+					walk(inner)
+				}
+				return
+			}
+			e = errors.Unwrap(e)
+		}
+	}
+	walk(err)
+	return errs
+}
@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// verboseError %+v输出比Error()更多内容的错误
+type verboseError struct{}
+
+func (verboseError) Error() string { return "verbose" }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "verbose details")
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func TestWithError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      []string
+		wantStack string
+	}{
+		{"nil", nil, nil, ""},
+		{"plain", errors.New("plain"), []string{`"error":"plain"`, `"error_type":"*errors.errorString"`}, ""},
+		{"wrapped", fmt.Errorf("outer: %w", errors.New("inner")), []string{`"error":"outer: inner"`, `"error_type":"*fmt.wrapError"`}, ""},
+		{"joined", errors.Join(errors.New("a"), pkgerrors.New("b")), []string{`"error_type":"*errors.joinError"`}, "TestWithError"},
+		{"pkg errors", pkgerrors.New("traced"), []string{`"error":"traced"`}, "TestWithError"},
+		{"pkg errors wrapped", fmt.Errorf("outer: %w", pkgerrors.New("traced")), []string{`"error":"outer: traced"`}, "TestWithError"},
+		{"formatter", verboseError{}, []string{`"error":"verbose"`}, "verbose details"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+			WithError(tt.err).Info("failed")
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("log missing %s:\n%s", want, got)
+				}
+			}
+			if tt.err == nil && strings.Contains(got, `"error"`) {
+				t.Errorf("nil error added fields:\n%s", got)
+			}
+			if hasStack := strings.Contains(got, `"error_stack"`); hasStack != (tt.wantStack != "") || !strings.Contains(got, tt.wantStack) {
+				t.Errorf("error_stack = %v, want %q:\n%s", hasStack, tt.wantStack, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sync"
+	"time"
+)
+
+// exitDrainTimeout 退出进程前等待异步输出写完的最长时间
+const exitDrainTimeout = 5 * time.Second
+
+// exitMu 保证只有第一个退出进程的日志调用写入缓冲并退出, 其余调用阻塞直到进程退出
+var exitMu sync.Mutex
+
+// exitHook 输出日志后写入o中所有缓冲和异步输出的日志, 再退出进程
+// zap默认的WriteThenFatal直接退出, MainBuffered等缓冲中的日志会丢失
+type exitHook struct {
+	o **outputs
+}
+
+func (h exitHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	exitMu.Lock()
+	if o := *h.o; o != nil {
+		_ = o.drain(exitDrainTimeout)
+	}
+	os.Exit(1)
+}
+
+// errorExitCore 输出error和dpanic级别的日志后退出进程, 用于严格模式
+type errorExitCore struct {
+	zapcore.Core
+	exit zapcore.CheckWriteHook
+}
+
+func (c errorExitCore) With(fields []zapcore.Field) zapcore.Core {
+	return errorExitCore{c.Core.With(fields), c.exit}
+}
+
+func (c errorExitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if ce != nil && ent.Level >= zapcore.ErrorLevel && ent.Level < zapcore.PanicLevel {
+		ce = ce.After(ent, c.exit)
+	}
+	return ce
+}
@@ -0,0 +1,177 @@
+package logs
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExitWritesBufferedLogs(t *testing.T) {
+	tests := []struct {
+		name string
+		conf LogConfig
+		// exit 在多个goroutine中并发调用, 进程应在第一个调用后退出
+		exit     func(i int)
+		wantLine string
+	}{
+		{"concurrent fatal", LogConfig{}, func(i int) { Fatalw("fatal", "n", i) }, "fatal"},
+		{"error exits", LogConfig{ErrorExits: true}, func(i int) { Errorw("strict", "n", i) }, "strict"},
+		{"panic exits", LogConfig{PanicExits: true}, func(i int) { Panicw("panicked", "n", i) }, "panicked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if os.Getenv(childEnv) == "1" {
+				c := tt.conf
+				c.Dir, c.FileName, c.DisableConsole, c.MainBuffered, c.StacktraceLevel = ".", "test", true, true, "disabled"
+				if err := InitLogSetting(&c); err != nil {
+					t.Fatal(err)
+				}
+				for i := 0; i < 100; i++ {
+					Infow("buffered", "n", i)
+				}
+				var wg sync.WaitGroup
+				for i := 0; i < 8; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						tt.exit(i)
+					}(i)
+				}
+				wg.Wait()
+				return
+			}
+
+			dir := t.TempDir()
+			out, err := runChild(t, dir)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("child exit = %v, want exit status 1\n%s", err, out)
+			}
+			lines := strings.Split(strings.TrimSuffix(readLog(t, filepath.Join(dir, "test.log")), "\n"), "\n")
+			var buffered, exits int
+			for _, line := range lines {
+				switch {
+				case strings.Contains(line, "buffered"):
+					buffered++
+				case strings.Contains(line, tt.wantLine):
+					exits++
+				}
+			}
+			if buffered != 100 {
+				t.Errorf("buffered lines = %d, want 100 written before exit", buffered)
+			}
+			// 第一个到达退出钩子的调用写入缓冲并退出, 其余调用在退出前可能已输出, 但不会截断
+			if exits == 0 {
+				t.Errorf("%s lines = 0, want at least one", tt.wantLine)
+			}
+			if last := lines[len(lines)-1]; !strings.HasSuffix(last, "}") {
+				t.Errorf("last line = %q, want a complete entry", last)
+			}
+		})
+	}
+}
+
+func TestStrictExits(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     LogConfig
+		log      func()
+		wantExit bool
+	}{
+		{"error", LogConfig{}, func() { Error("strict") }, false},
+		{"error exits", LogConfig{ErrorExits: true}, func() { Error("strict") }, true},
+		{"dpanic exits", LogConfig{ErrorExits: true}, func() { DPanic("strict") }, true},
+		{"warn does not exit", LogConfig{ErrorExits: true}, func() { Warn("strict") }, false},
+		{"named error exits", LogConfig{ErrorExits: true}, func() { Named("db").Error("strict") }, true},
+		{"Desugar error exits", LogConfig{ErrorExits: true}, func() { Desugar().Error("strict") }, true},
+		{"With error exits", LogConfig{ErrorExits: true}, func() { With("k", "v").Error("strict") }, true},
+		{"panic recovered", LogConfig{}, func() {
+			defer func() { _ = recover() }()
+			Panic("strict")
+		}, false},
+		{"panic exits", LogConfig{PanicExits: true}, func() {
+			defer func() { _ = recover() }()
+			Panic("strict")
+		}, true},
+		{"PanicWith exits", LogConfig{PanicExits: true}, func() {
+			defer func() { _ = recover() }()
+			PanicWith("strict", "k", "v")
+		}, true},
+		{"panic exits without ErrorExits error", LogConfig{PanicExits: true}, func() { Error("strict") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if os.Getenv(childEnv) == "1" {
+				c := tt.conf
+				c.Dir, c.FileName, c.DisableConsole, c.StacktraceLevel = ".", "test", true, "disabled"
+				if err := InitLogSetting(&c); err != nil {
+					t.Fatal(err)
+				}
+				tt.log()
+				Info("after")
+				_ = Sync()
+				return
+			}
+
+			dir := t.TempDir()
+			out, err := runChild(t, dir)
+			var exitErr *exec.ExitError
+			if tt.wantExit && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 1) {
+				t.Fatalf("child exit = %v, want exit status 1\n%s", err, out)
+			}
+			if !tt.wantExit && err != nil {
+				t.Fatalf("child exit = %v, want success\n%s", err, out)
+			}
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			if !strings.Contains(got, "strict") {
+				t.Errorf("log = %s, want the strict entry written before exit", got)
+			}
+			if strings.Contains(got, "after") == tt.wantExit {
+				t.Errorf("log = %s, want later entries only when not exiting", got)
+			}
+		})
+	}
+}
+
+func TestFatalWithoutExitHook(t *testing.T) {
+	// 外部logger的退出钩子不退出进程时, 之后的Fatal调用不能阻塞
+	tests := []struct {
+		name string
+		hook zapcore.CheckWriteAction
+	}{
+		{"WriteThenGoexit", zapcore.WriteThenGoexit},
+		{"WriteThenPanic", zapcore.WriteThenPanic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{})
+			core, recorded := observer.New(zapcore.DebugLevel)
+			SetLogger(zap.New(core, zap.WithFatalHook(tt.hook)))
+
+			for i := 0; i < 3; i++ {
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					defer func() { _ = recover() }()
+					Fatalw("fatal", "n", i)
+				}()
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("Fatal call %d blocked", i)
+				}
+			}
+			if got := recorded.FilterMessage("fatal").Len(); got != 3 {
+				t.Errorf("fatal entries = %d, want 3", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sort"
+	"time"
+)
+
+// Field 结构化日志字段, 与zap.Field相同, 使用时无需引入zap
+type Field = zapcore.Field
+
+// L 获取非sugar的logger, 配合Field使用可以避免反射带来的开销
+//
+//	logs.L().Info("request done", logs.Int64("cost", cost), logs.Err(err))
+func L() *zap.Logger {
+	return Desugar()
+}
+
+// DebugF 以debug级别输出msg和fields, 不经过sugar, 没有反射和参数转换的开销
+//
+//	logs.InfoF("request done", logs.String("path", path), logs.Int("status", status))
+func DebugF(msg string, fields ...Field) {
+	lz().Debug(msg, fields...)
+}
+
+func InfoF(msg string, fields ...Field) {
+	lz().Info(msg, fields...)
+}
+
+func WarnF(msg string, fields ...Field) {
+	lz().Warn(msg, fields...)
+}
+
+func ErrorF(msg string, fields ...Field) {
+	lz().Error(msg, fields...)
+}
+
+func String(key string, val string) Field {
+	return zap.String(key, val)
+}
+
+func Int(key string, val int) Field {
+	return zap.Int(key, val)
+}
+
+func Int64(key string, val int64) Field {
+	return zap.Int64(key, val)
+}
+
+func Float64(key string, val float64) Field {
+	return zap.Float64(key, val)
+}
+
+func Bool(key string, val bool) Field {
+	return zap.Bool(key, val)
+}
+
+func Duration(key string, val time.Duration) Field {
+	return zap.Duration(key, val)
+}
+
+func Time(key string, val time.Time) Field {
+	return zap.Time(key, val)
+}
+
+// Err 生成key为error的字段, 与zap.Error相同, 为避免与Error函数重名而改名
+func Err(err error) Field {
+	return zap.Error(err)
+}
+
+func Stringer(key string, val fmt.Stringer) Field {
+	return zap.Stringer(key, val)
+}
+
+func Any(key string, val interface{}) Field {
+	return zap.Any(key, val)
+}
+
+// initialFields 每条日志附加的字段, 依次为service env host pid和按键排序的InitialFields
+func initialFields(conf *LogConfig) []Field {
+	values := map[string]interface{}{"pid": os.Getpid()}
+	if host, err := os.Hostname(); err == nil {
+		values["host"] = host
+	}
+	if conf.Service != "" {
+		values["service"] = conf.Service
+	}
+	if conf.Env != "" {
+		values["env"] = conf.Env
+	}
+	keys := make([]string, 0, len(conf.InitialFields))
+	for k, v := range conf.InitialFields {
+		if _, ok := values[k]; !ok {
+			keys = append(keys, k)
+		}
+		values[k] = v
+	}
+	sort.Strings(keys)
+
+	var fields []Field
+	for _, k := range append([]string{"service", "env", "host", "pid"}, keys...) {
+		if v, ok := values[k]; ok {
+			fields = append(fields, zap.Any(k, v))
+		}
+	}
+	return fields
+}
+
+// mapFields 将map转为按键排序的字段
+func mapFields(m map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Any(k, m[k]))
+	}
+	return fields
+}
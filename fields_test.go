@@ -0,0 +1,165 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldConstructors(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		field Field
+		want  string
+	}{
+		{"String", String("k", "v"), `"k":"v"`},
+		{"Int", Int("k", -1), `"k":-1`},
+		{"Int64", Int64("k", 1<<40), `"k":1099511627776`},
+		{"Float64", Float64("k", 1.5), `"k":1.5`},
+		{"Bool", Bool("k", true), `"k":true`},
+		{"Duration", Duration("k", 1500*time.Millisecond), `"k":1.5`},
+		{"Time", Time("k", at), `"k":"2024-01-02`},
+		{"Err", Err(errors.New("boom")), `"error":"boom"`},
+		{"Stringer", Stringer("k", net.IPv4(127, 0, 0, 1)), `"k":"127.0.0.1"`},
+		{"Any", Any("k", []int{1, 2}), `"k":[1,2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Encoding: "json"})
+			L().Info("typed", tt.field)
+			_ = Sync()
+
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("log = %s, want %s", got, tt.want)
+			}
+			if !strings.Contains(got, "fields_test.go") {
+				t.Errorf("log = %s, want the caller of L()", got)
+			}
+		})
+	}
+}
+
+func TestInitialFields(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := []struct {
+		name string
+		conf LogConfig
+		want []string
+	}{
+		{"host and pid", LogConfig{}, []string{`"host":"` + host + `"`, fmt.Sprintf(`"pid":%d`, os.Getpid())}},
+		{"service and env", LogConfig{Service: "api", Env: "prod"}, []string{`"service":"api","env":"prod","host":`}},
+		{"initial fields sorted", LogConfig{InitialFields: map[string]interface{}{"region": "cn", "az": 2}}, []string{`"pid":` + fmt.Sprint(os.Getpid()) + `,"az":2,"region":"cn"`}},
+		{"override host", LogConfig{InitialFields: map[string]interface{}{"host": "pod-1"}}, []string{`"host":"pod-1"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			c.Level = "info"
+			c.Encoding = "json"
+			_, stderr := initConsoleLogger(t, &c)
+			Info("plain")
+			Error("plain error")
+			_ = Sync()
+
+			outputs := map[string]string{
+				"file":       readLog(t, filepath.Join(c.Dir, "test.log")),
+				"error file": readLog(t, filepath.Join(c.Dir, "test_err.log")),
+			}
+			for name, got := range outputs {
+				for _, want := range tt.want {
+					if !strings.Contains(got, want) {
+						t.Errorf("%s = %s, want %s", name, got, want)
+					}
+				}
+			}
+			// 控制台使用console格式, 只检查字段名
+			if got := stderr(); !strings.Contains(got, `"pid": `) {
+				t.Errorf("stderr = %q, want the initial fields", got)
+			}
+		})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	tests := []struct {
+		name string
+		lg   func() *Logger
+		want string
+	}{
+		{"sorted", func() *Logger { return WithFields(map[string]interface{}{"c": 3, "a": 1, "b": 2}) }, `"a":1,"b":2,"c":3`},
+		{"nested map", func() *Logger {
+			return WithFields(map[string]interface{}{"user": map[string]interface{}{"id": 1, "name": "x"}})
+		}, `"user":{"id":1,"name":"x"}`},
+		{"nil", func() *Logger { return WithFields(nil) }, fmt.Sprintf(`"pid":%d}`, os.Getpid())},
+		{"with With", func() *Logger { return With("req", "r1").WithFields(map[string]interface{}{"b": 2, "a": 1}) }, `"req":"r1","a":1,"b":2`},
+		{"Named", func() *Logger { return Named("db").WithFields(map[string]interface{}{"table": "t"}) }, `"logger":"db"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+			tt.lg().Info("fields line")
+			_ = Sync()
+
+			var line string
+			for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(l, "fields line") {
+					line = l
+				}
+			}
+			if !strings.Contains(line, tt.want) {
+				t.Errorf("line = %s, want %s", line, tt.want)
+			}
+		})
+	}
+	lg := Named("x")
+	if got := lg.WithFields(nil); got != lg {
+		t.Error("WithFields(nil) did not return the receiver")
+	}
+}
+
+func TestLeveledFieldFunctions(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(msg string, fields ...Field)
+		want  string
+		level string
+	}{
+		{"DebugF", DebugF, `"level":"DEBUG"`, "debug"},
+		{"InfoF", InfoF, `"level":"INFO"`, "debug"},
+		{"WarnF", WarnF, `"level":"WARN"`, "debug"},
+		{"ErrorF", ErrorF, `"level":"ERROR"`, "debug"},
+		{"DebugF filtered", DebugF, "", "info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: tt.level, Encoding: "json", StacktraceLevel: "disabled"})
+			tt.write("typed line", String("k", "v"), Int("n", 1))
+			_ = Sync()
+
+			var line string
+			for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(l, "typed line") {
+					line = l
+				}
+			}
+			if tt.want == "" {
+				if line != "" {
+					t.Errorf("line = %s, want it filtered", line)
+				}
+				return
+			}
+			for _, want := range []string{tt.want, `"k":"v","n":1`, `"caller":"module/fields_test.go:`} {
+				if !strings.Contains(line, want) {
+					t.Errorf("line = %s, want %s", line, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package logs
+
+import (
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// rollingFile 按大小轮转的日志文件, 可以在运行中修改清理配置
+// lumberjack在后台清理时不加锁读取MaxAge和MaxBackups, 修改时换用新的lumberjack.Logger
+type rollingFile struct {
+	Filename string
+
+	mu sync.RWMutex
+	l  *lumberjack.Logger
+}
+
+var (
+	rollingFilesMu sync.Mutex
+	// rollingFiles 按路径共用的日志文件. 重新初始化时还在使用之前logger的goroutine与新的logger写入同一个rollingFile,
+	// 两个lumberjack.Logger同时打开和轮转同一个文件时, 以O_TRUNC新建文件会丢失另一个刚写入的日志
+	rollingFiles = map[string]*rollingFile{}
+)
+
+// newRollingFile 获取l.Filename对应的日志文件, 已经存在时按l的配置修改
+func newRollingFile(l *lumberjack.Logger) *rollingFile {
+	key := l.Filename
+	if abs, err := filepath.Abs(key); err == nil {
+		key = abs
+	}
+	rollingFilesMu.Lock()
+	defer rollingFilesMu.Unlock()
+	if f, ok := rollingFiles[key]; ok {
+		_ = f.reset(l)
+		return f
+	}
+	f := &rollingFile{Filename: l.Filename, l: l}
+	rollingFiles[key] = f
+	return f
+}
+
+func (f *rollingFile) Write(p []byte) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.l.Write(p)
+}
+
+// Rotate 立即轮转
+func (f *rollingFile) Rotate() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.l.Rotate()
+}
+
+// Close 关闭文件, 之后写入时重新打开
+func (f *rollingFile) Close() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.l.Close()
+}
+
+// retention 当前的保存天数和保留个数
+func (f *rollingFile) retention() (maxAge, maxBackups int) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.l.MaxAge, f.l.MaxBackups
+}
+
+// setRetention 修改保存天数和保留个数, 关闭当前文件, 下次写入时重新打开并按新的配置清理
+func (f *rollingFile) setRetention(maxAge, maxBackups int) error {
+	f.mu.RLock()
+	l := &lumberjack.Logger{
+		Filename:   f.l.Filename,
+		MaxSize:    f.l.MaxSize,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		LocalTime:  f.l.LocalTime,
+		Compress:   f.l.Compress,
+	}
+	f.mu.RUnlock()
+	return f.reset(l)
+}
+
+// reset 配置与l不同时关闭当前文件并换用l, 下次写入时重新打开
+func (f *rollingFile) reset(l *lumberjack.Logger) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old := f.l
+	if old.MaxSize == l.MaxSize && old.MaxAge == l.MaxAge && old.MaxBackups == l.MaxBackups &&
+		old.LocalTime == l.LocalTime && old.Compress == l.Compress {
+		return nil
+	}
+	f.l = l
+	return old.Close()
+}
+
+// fileWriter 记录最近一次写入文件是否失败
+type fileWriter struct {
+	io.Writer
+	failed int32
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if err != nil {
+		atomic.StoreInt32(&w.failed, 1)
+	} else {
+		atomic.StoreInt32(&w.failed, 0)
+	}
+	return n, err
+}
+
+func (w *fileWriter) writable() bool {
+	return atomic.LoadInt32(&w.failed) == 0
+}
+
+// FileLoggingActive 文件日志是否处于启用状态, 且所有文件(包括MirrorDirs中的文件)最近一次写入没有失败
+func FileLoggingActive() bool {
+	if len(std().fileWriters) == 0 {
+		return false
+	}
+	for _, w := range std().fileWriters {
+		if !w.writable() {
+			return false
+		}
+	}
+	return true
+}
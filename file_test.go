@@ -0,0 +1,54 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLoggingActive(t *testing.T) {
+	// broken 文件下的目录无法创建, 写入失败
+	broken := func(t *testing.T) string {
+		blocker := filepath.Join(t.TempDir(), "blocker")
+		if err := os.WriteFile(blocker, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filepath.Join(blocker, "mirror")
+	}
+	// brokenErr 错误日志文件是指向不存在目录的链接, 只有错误日志文件写入失败
+	brokenErr := func(t *testing.T) string {
+		dir := t.TempDir()
+		if err := os.Symlink(filepath.Join(dir, "missing", "test_err.log"), filepath.Join(dir, "test_err.log")); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	tests := []struct {
+		name    string
+		disable bool
+		mirrors func(t *testing.T) []string
+		// errLine 为true时输出error日志, 同时写入错误日志文件
+		errLine bool
+		want    bool
+	}{
+		{"no mirrors", false, func(t *testing.T) []string { return nil }, false, true},
+		{"writable mirror", false, func(t *testing.T) []string { return []string{t.TempDir()} }, true, true},
+		{"broken mirror", false, func(t *testing.T) []string { return []string{t.TempDir(), broken(t)} }, false, false},
+		{"broken error mirror", false, func(t *testing.T) []string { return []string{brokenErr(t)} }, true, false},
+		{"broken error mirror unused", false, func(t *testing.T) []string { return []string{brokenErr(t)} }, false, true},
+		{"file disabled", true, func(t *testing.T) []string { return nil }, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "info", DisableFile: tt.disable, MirrorDirs: tt.mirrors(t)})
+			if tt.errLine {
+				Error("file line")
+			} else {
+				Info("file line")
+			}
+			if got := FileLoggingActive(); got != tt.want {
+				t.Errorf("FileLoggingActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
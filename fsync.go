@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// fsyncer 定时将日志文件的内容fsync到磁盘
+// lumberjack不提供Sync, 通过重新打开文件调用Sync
+type fsyncer struct {
+	interval time.Duration
+	files    []string
+	// flush fsync前写入缓冲中的内容
+	flush []func() error
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newFsyncer(interval time.Duration, flush []func() error, files ...string) *fsyncer {
+	return &fsyncer{
+		interval: interval,
+		files:    files,
+		flush:    flush,
+	}
+}
+
+// start 启动后台fsync, 已启动时不做任何事
+func (f *fsyncer) start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stop != nil {
+		return
+	}
+	f.stop = make(chan struct{})
+	f.done = make(chan struct{})
+	go f.run(f.stop, f.done)
+}
+
+// close 停止后台fsync并等待正在进行的fsync完成
+func (f *fsyncer) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stop == nil {
+		return
+	}
+	close(f.stop)
+	<-f.done
+	f.stop, f.done = nil, nil
+}
+
+func (f *fsyncer) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.sync()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (f *fsyncer) sync() {
+	for _, flush := range f.flush {
+		_ = flush()
+	}
+	for _, name := range f.files {
+		// 文件还未创建时跳过
+		file, err := os.OpenFile(name, os.O_WRONLY, 0)
+		if err != nil {
+			continue
+		}
+		_ = file.Sync()
+		_ = file.Close()
+	}
+}
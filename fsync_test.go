@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFsyncer(t *testing.T) {
+	tests := []struct {
+		name  string
+		files func(dir string) []string
+	}{
+		{"existing files", func(dir string) []string {
+			name := filepath.Join(dir, "a.log")
+			if err := os.WriteFile(name, []byte("line\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			return []string{name}
+		}},
+		{"missing file skipped", func(dir string) []string {
+			return []string{filepath.Join(dir, "missing.log")}
+		}},
+		{"no files", func(string) []string { return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var flushed atomic.Int32
+			flush := func() error {
+				flushed.Add(1)
+				return nil
+			}
+			f := newFsyncer(5*time.Millisecond, []func() error{flush, flush}, tt.files(dir)...)
+			f.start()
+			f.start()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for flushed.Load() < 4 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			f.close()
+			f.close()
+			got := flushed.Load()
+			if got < 4 || got%2 != 0 {
+				t.Fatalf("flushed = %d, want an even count of at least 4", got)
+			}
+
+			// close等待后台goroutine退出, 之后不再flush
+			time.Sleep(20 * time.Millisecond)
+			if after := flushed.Load(); after != got {
+				t.Errorf("flushed = %d after close, want %d", after, got)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "missing.log")); !os.IsNotExist(err) {
+				t.Errorf("missing.log stat = %v, want not created", err)
+			}
+		})
+	}
+}
+
+func TestPeriodicFsync(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   LogConfig
+		active bool
+	}{
+		{"enabled", LogConfig{PeriodicFsync: 10 * time.Millisecond, MainBuffered: true}, true},
+		{"disabled", LogConfig{MainBuffered: true}, false},
+		{"file disabled", LogConfig{PeriodicFsync: 10 * time.Millisecond, DisableFile: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			dir := initTestLogger(t, &c)
+			if got := std().files().fsyncer != nil; got != tt.active {
+				t.Fatalf("fsyncer active = %v, want %v", got, tt.active)
+			}
+			if !tt.active {
+				return
+			}
+
+			// 缓冲默认30s才写入文件, 不调用Sync时只能由定时fsync写入
+			Info("periodic fsync line")
+			name := filepath.Join(dir, "test.log")
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				if b, _ := os.ReadFile(name); strings.Contains(string(b), "periodic fsync line") {
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			t.Errorf("log = %s, want the buffered line flushed", readLog(t, name))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// gcMonitorInterval GC监控读取GC统计的间隔, 测试时修改
+var gcMonitorInterval = time.Second
+
+// heapObjectsMetric 堆上对象占用的字节数, 与MemStats.HeapAlloc相同
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// StartGCMonitor 监控GC暂停时间, 超过threshold时以warn级别输出, 返回的函数停止监控
+// 每隔gcMonitorInterval读取一次GC统计, 间隔内超过256次GC时只检查最近的256次
+// 使用debug.ReadGCStats和runtime/metrics读取, 不会像ReadMemStats一样暂停程序
+//
+//	stop := logs.StartGCMonitor(10 * time.Millisecond)
+//	defer stop()
+func StartGCMonitor(threshold time.Duration) (stop func()) {
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+	lastGC := stats.NumGC
+	interval := gcMonitorInterval
+
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heap := []metrics.Sample{{Name: heapObjectsMetric}}
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			debug.ReadGCStats(&stats)
+			// Pause按时间倒序, 第i个为第NumGC-i次GC的暂停时间
+			n := stats.NumGC - lastGC
+			if n > int64(len(stats.Pause)) {
+				n = int64(len(stats.Pause))
+			}
+			lastGC = stats.NumGC
+			if n <= 0 {
+				continue
+			}
+			metrics.Read(heap)
+			var heapAlloc uint64
+			if heap[0].Value.Kind() == metrics.KindUint64 {
+				heapAlloc = heap[0].Value.Uint64()
+			}
+			// 监控协程的调用位置没有意义, 不输出caller
+			logger := l().Desugar().WithOptions(zap.WithCaller(false)).Sugar()
+			for i := n - 1; i >= 0; i-- {
+				if pause := stats.Pause[i]; pause > threshold {
+					logger.Warnw("slow gc pause",
+						"pause", pause,
+						"threshold", threshold,
+						"num_gc", stats.NumGC-i,
+						"heap_alloc", heapAlloc,
+					)
+				}
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStartGCMonitor(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		want      bool
+	}{
+		{"over threshold", 0, true},
+		{"under threshold", time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "info")
+			prev := gcMonitorInterval
+			gcMonitorInterval = 5 * time.Millisecond
+			defer func() { gcMonitorInterval = prev }()
+
+			stop := StartGCMonitor(tt.threshold)
+			defer stop()
+			// 等待多个检查周期, 期间持续触发GC
+			deadline := time.Now().Add(5 * time.Second)
+			for i := 0; time.Now().Before(deadline); i++ {
+				runtime.GC()
+				time.Sleep(gcMonitorInterval)
+				if recorded.FilterMessage("slow gc pause").Len() > 0 || !tt.want && i >= 20 {
+					break
+				}
+			}
+			stop()
+
+			entries := recorded.FilterMessage("slow gc pause").All()
+			if got := len(entries) > 0; got != tt.want {
+				t.Fatalf("slow gc entries = %d, want logged %v", len(entries), tt.want)
+			}
+			for _, e := range entries {
+				if e.Caller.Defined {
+					t.Errorf("caller = %s, want none", e.Caller)
+				}
+				fields := e.ContextMap()
+				if pause, ok := fields["pause"].(time.Duration); !ok || pause <= tt.threshold {
+					t.Errorf("pause = %v, want a duration over %s", fields["pause"], tt.threshold)
+				}
+				if n, ok := fields["num_gc"].(int64); !ok || n <= 0 {
+					t.Errorf("num_gc = %v, want a positive int64", fields["num_gc"])
+				}
+				if heap, ok := fields["heap_alloc"].(uint64); !ok || heap == 0 {
+					t.Errorf("heap_alloc = %v, want a positive uint64", fields["heap_alloc"])
+				}
+			}
+		})
+	}
+}
+
+func TestStartGCMonitorStop(t *testing.T) {
+	recorded := observeDefault(t, "info")
+	prev := gcMonitorInterval
+	gcMonitorInterval = 5 * time.Millisecond
+	defer func() { gcMonitorInterval = prev }()
+
+	stop := StartGCMonitor(0)
+	stop()
+	// 多次调用不会panic, 停止后不再输出
+	stop()
+	runtime.GC()
+	time.Sleep(20 * gcMonitorInterval)
+	if got := recorded.FilterMessage("slow gc pause").Len(); got != 0 {
+		t.Errorf("entries after stop = %d, want 0", got)
+	}
+}
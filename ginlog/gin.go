@@ -0,0 +1,100 @@
+// Package ginlog 将gin的请求日志和panic写入默认logger的中间件
+package ginlog
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Logger 输出请求日志的gin中间件, 字段为method path status latency client_ip bytes
+// 状态码4xx以warn级别输出, 5xx以error级别输出, 其他以info级别输出, 日志附加字段system=gin
+//
+//	r := gin.New()
+//	r.Use(ginlog.Logger(), ginlog.Recovery(true))
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		if raw := c.Request.URL.RawQuery; raw != "" {
+			path += "?" + raw
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); errs != "" {
+			fields = append(fields, "errors", errs)
+		}
+		logger := ginLogger()
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Errorw("request", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warnw("request", fields...)
+		default:
+			logger.Infow("request", fields...)
+		}
+	}
+}
+
+// Recovery 恢复handler中panic的gin中间件, 与logs.PrintPanicStack相同输出panic的值, 附加字段request为请求行
+// stack为true时同时输出调用栈, 输出后返回500
+// 客户端断开连接导致的panic以warn级别输出, 不输出调用栈, 也不再写入响应
+func Recovery(stack bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			x := recover()
+			if x == nil {
+				return
+			}
+			logger := ginLogger().With("request", c.Request.Method+" "+c.Request.URL.RequestURI()+" "+c.Request.Proto)
+			if fields := logs.ContextFields(c.Request.Context()); len(fields) > 0 {
+				logger = logger.With(fields...)
+			}
+			if err, ok := x.(error); ok && isBrokenPipe(err) {
+				logger.Warnw("connection broken", "error", err)
+				_ = c.Error(err)
+				c.Abort()
+				return
+			}
+			logs.LogPanic(logger, x, stack)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+// ginLogger 中间件使用的logger, 调用位置为gin内部, 不输出调用位置
+func ginLogger() *zap.SugaredLogger {
+	return logs.Desugar().WithOptions(zap.WithCaller(false)).Sugar().With("system", "gin")
+}
+
+// isBrokenPipe 判断err是否为客户端断开连接导致的写入错误
+func isBrokenPipe(err error) bool {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if !errors.As(opErr, &sysErr) {
+		return false
+	}
+	msg := strings.ToLower(fmt.Sprint(sysErr.Err))
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
@@ -0,0 +1,107 @@
+package ginlog
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// observe 使用临时目录初始化默认logger, 返回写入默认logger的日志
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+	core, recorded := observer.New(zapcore.DebugLevel)
+	t.Cleanup(logs.AddCore(core))
+	return recorded
+}
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		target    string
+		wantLevel zapcore.Level
+		wantPath  string
+	}{
+		{"ok", http.StatusOK, "/users?id=1", zapcore.InfoLevel, "/users?id=1"},
+		{"client error", http.StatusNotFound, "/missing", zapcore.WarnLevel, "/missing"},
+		{"server error", http.StatusBadGateway, "/upstream", zapcore.ErrorLevel, "/upstream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			r := gin.New()
+			r.Use(Logger())
+			r.GET("/*path", func(c *gin.Context) { c.String(tt.status, "body") })
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			entries := recorded.FilterMessage("request").All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			fields := e.ContextMap()
+			if e.Level != tt.wantLevel || fields["path"] != tt.wantPath || fields["status"] != int64(tt.status) || fields["system"] != "gin" {
+				t.Errorf("entry = %s %v, want %s path %s", e.Level, fields, tt.wantLevel, tt.wantPath)
+			}
+			if fields["bytes"] != int64(len("body")) || fields["method"] != http.MethodGet {
+				t.Errorf("fields = %v", fields)
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	brokenPipe := &net.OpError{Op: "write", Err: &os.SyscallError{Syscall: "write", Err: errors.New("broken pipe")}}
+	tests := []struct {
+		name       string
+		stack      bool
+		value      interface{}
+		wantLevel  zapcore.Level
+		wantStatus int
+		wantFrames bool
+	}{
+		{"with stack", true, "boom", zapcore.ErrorLevel, http.StatusInternalServerError, true},
+		{"without stack", false, "boom", zapcore.ErrorLevel, http.StatusInternalServerError, false},
+		{"broken pipe", true, brokenPipe, zapcore.WarnLevel, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			r := gin.New()
+			r.Use(Recovery(tt.stack))
+			r.GET("/panic", func(c *gin.Context) { panic(tt.value) })
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			entries := recorded.All()
+			if len(entries) == 0 {
+				t.Fatal("no entries logged")
+			}
+			if e := entries[0]; e.Level != tt.wantLevel || e.ContextMap()["request"] != "GET /panic HTTP/1.1" {
+				t.Errorf("entry = %s %v, want %s with the request line", e.Level, e.ContextMap(), tt.wantLevel)
+			}
+			var frames bool
+			for _, e := range entries {
+				frames = frames || strings.HasPrefix(e.Message, "frame ")
+			}
+			if frames != tt.wantFrames {
+				t.Errorf("stack frames logged = %v, want %v", frames, tt.wantFrames)
+			}
+		})
+	}
+}
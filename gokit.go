@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"strings"
+)
+
+// gokitMissingValue keyvals为奇数个时最后一个key的值, 与go-kit的ErrMissingValue相同
+const gokitMissingValue = "(MISSING)"
+
+// GoKitLogger 写入默认logger的go-kit日志, 实现go-kit的log.Logger, 不依赖go-kit
+// level对应的值决定日志级别(最高为error), msg或message对应的值作为日志内容, 其余的键值对作为日志字段
+type GoKitLogger struct {
+	l     *zap.Logger
+	level zapcore.Level
+}
+
+// NewGoKitLogger 获取写入默认logger的go-kit日志, keyvals中没有level时以defaultLevel输出(最高为error), 重新初始化后依然有效
+//
+//	logger := log.With(logs.NewGoKitLogger("info"), "component", "billing")
+//	level.Warn(logger).Log("msg", "retrying", "attempt", n)
+func NewGoKitLogger(defaultLevel string) (*GoKitLogger, error) {
+	lvl, err := parseLevel(defaultLevel)
+	if err != nil {
+		return nil, fmt.Errorf("logs: invalid level %q, accepted values: %s", defaultLevel, levelNames)
+	}
+	return &GoKitLogger{l: Desugar(), level: minLevel(lvl, zapcore.ErrorLevel)}, nil
+}
+
+// Log 输出一条日志, 总是返回nil
+func (g *GoKitLogger) Log(keyvals ...interface{}) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, gokitMissingValue)
+	}
+	lvl, msg := g.level, ""
+	fields := make([]Field, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, val := fmt.Sprint(keyvals[i]), keyvals[i+1]
+		switch key {
+		case "level":
+			// go-kit的level值实现了fmt.Stringer, 无法解析时保留为字段
+			// Log不panic也不退出进程, dpanic panic fatal以error级别输出
+			if parsed, err := parseLevel(fmt.Sprint(val)); err == nil {
+				lvl = minLevel(parsed, zapcore.ErrorLevel)
+				continue
+			}
+		case "msg", "message":
+			if msg == "" {
+				msg = fmt.Sprint(val)
+				continue
+			}
+		}
+		fields = append(fields, zap.Any(key, val))
+	}
+
+	if !g.l.Core().Enabled(lvl) {
+		return nil
+	}
+	// 跳过Log和log.With等go-kit内部的调用层级
+	if ce := g.l.WithOptions(zap.AddCallerSkip(1+gokitDepth())).Check(lvl, msg); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// gokitDepth Log的调用方中连续的go-kit栈帧数量
+func gokitDepth() int {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	depth := 0
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/go-kit/") {
+			return depth
+		}
+		depth++
+		if !more {
+			return depth
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gokitLevel 与go-kit的level值相同, 实现fmt.Stringer
+type gokitLevel string
+
+func (l gokitLevel) String() string { return string(l) }
+
+func TestGoKitLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyvals []interface{}
+		want    zapcore.Level
+		wantMsg string
+		fields  map[string]interface{}
+	}{
+		{"default level", []interface{}{"msg", "gokit line", "k", "v"}, zapcore.InfoLevel, "gokit line", map[string]interface{}{"k": "v"}},
+		{"level string", []interface{}{"level", "warn", "msg", "gokit line"}, zapcore.WarnLevel, "gokit line", nil},
+		{"level Stringer", []interface{}{"level", gokitLevel("error"), "msg", "gokit line"}, zapcore.ErrorLevel, "gokit line", nil},
+		{"level trace", []interface{}{"level", "trace", "msg", "gokit line"}, TraceLevel, "gokit line", nil},
+		{"level after msg", []interface{}{"msg", "gokit line", "level", gokitLevel("debug")}, zapcore.DebugLevel, "gokit line", nil},
+		{"unknown level kept", []interface{}{"level", "loud", "msg", "gokit line"}, zapcore.InfoLevel, "gokit line", map[string]interface{}{"level": "loud"}},
+		{"panic level not raised", []interface{}{"level", "panic", "msg", "gokit line"}, zapcore.ErrorLevel, "gokit line", nil},
+		{"fatal level not raised", []interface{}{"level", "fatal", "msg", "gokit line"}, zapcore.ErrorLevel, "gokit line", nil},
+		{"message key", []interface{}{"message", "gokit line"}, zapcore.InfoLevel, "gokit line", nil},
+		{"second msg kept", []interface{}{"msg", "gokit line", "msg", "again"}, zapcore.InfoLevel, "gokit line", map[string]interface{}{"msg": "again"}},
+		{"no msg", []interface{}{"k", 1}, zapcore.InfoLevel, "", map[string]interface{}{"k": int64(1)}},
+		{"odd keyvals", []interface{}{"msg", "gokit line", "dangling"}, zapcore.InfoLevel, "gokit line", map[string]interface{}{"dangling": "(MISSING)"}},
+		{"single key", []interface{}{"only"}, zapcore.InfoLevel, "", map[string]interface{}{"only": "(MISSING)"}},
+		{"non-string key", []interface{}{42, "v", "msg", "gokit line"}, zapcore.InfoLevel, "gokit line", map[string]interface{}{"42": "v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "trace")
+			g, err := NewGoKitLogger("info")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.Log(tt.keyvals...); err != nil {
+				t.Errorf("Log = %v, want nil", err)
+			}
+
+			entries := recorded.All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.Message != tt.wantMsg {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.Message, tt.want, tt.wantMsg)
+			}
+			if !strings.HasSuffix(e.Caller.File, "gokit_test.go") {
+				t.Errorf("caller = %s, want gokit_test.go", e.Caller.File)
+			}
+			fields := e.ContextMap()
+			for k, want := range tt.fields {
+				if got := fields[k]; got != want {
+					t.Errorf("%s = %v (%T), want %v", k, got, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewGoKitLogger(t *testing.T) {
+	tests := []struct {
+		level   string
+		log     string
+		wantErr bool
+		written bool
+	}{
+		{"debug", "debug", false, true},
+		{"debug", "info", false, false},
+		{"warn", "info", false, true},
+		{"trace", "debug", false, false},
+		{"fatal", "error", false, true},
+		{"loud", "debug", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level+" at "+tt.log, func(t *testing.T) {
+			recorded := observeDefault(t, tt.log)
+			g, err := NewGoKitLogger(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			_ = g.Log("msg", "default level")
+			if got := recorded.Len() == 1; got != tt.written {
+				t.Errorf("written = %v, want %v at level %s", got, tt.written, tt.log)
+			}
+		})
+	}
+}
+
+func TestGoKitLoggerConcurrent(t *testing.T) {
+	g, err := NewGoKitLogger("info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 在初始化之前创建, 重新初始化后依然写入默认logger
+	recorded := observeDefault(t, "debug")
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = g.Log("level", "warn", "msg", "gokit line", "i", i)
+		}(i)
+	}
+	wg.Wait()
+	if got := recorded.FilterMessage("gokit line").FilterLevelExact(zapcore.WarnLevel).Len(); got != n {
+		t.Errorf("entries = %d, want %d", got, n)
+	}
+}
@@ -0,0 +1,110 @@
+// Package gormlog 将gorm的SQL日志写入默认logger
+package gormlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap"
+	gormlogger "gorm.io/gorm/logger"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Logger 写入默认logger的gorm日志, 实现gorm的logger.Interface, 日志附加字段system=gorm
+// SQL以结构化字段sql rows elapsed_ms source输出, 执行出错时以error级别输出, 超过慢查询阈值时以warn级别输出
+type Logger struct {
+	l             *zap.SugaredLogger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+	// IgnoreRecordNotFound true 查询不到记录不作为错误输出, New默认为true
+	IgnoreRecordNotFound bool
+	// now 获取当前时间, 默认time.Now
+	now func() time.Time
+}
+
+// New 获取写入默认logger的gorm日志, slowThreshold为慢查询阈值, 0为不检查慢查询
+// 默认输出所有SQL, 最终是否输出由日志级别决定, 可通过LogMode只输出警告或错误
+//
+//	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormlog.New(200 * time.Millisecond)})
+func New(slowThreshold time.Duration) *Logger {
+	return &Logger{
+		// 调用方为gorm内部, 调用位置通过source字段输出
+		l:                    logs.Desugar().WithOptions(zap.WithCaller(false)).Sugar().With("system", "gorm"),
+		level:                gormlogger.Info,
+		slowThreshold:        slowThreshold,
+		IgnoreRecordNotFound: true,
+		now:                  time.Now,
+	}
+}
+
+// LogMode 获取使用gorm日志级别level的Logger, Silent不输出, Error只输出错误, Warn输出错误和慢查询
+func (g *Logger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *g
+	c.level = level
+	return &c
+}
+
+func (g *Logger) Info(_ context.Context, msg string, data ...interface{}) {
+	if g.level >= gormlogger.Info {
+		g.l.With("source", gormSource()).Infof(msg, data...)
+	}
+}
+
+func (g *Logger) Warn(_ context.Context, msg string, data ...interface{}) {
+	if g.level >= gormlogger.Warn {
+		g.l.With("source", gormSource()).Warnf(msg, data...)
+	}
+}
+
+func (g *Logger) Error(_ context.Context, msg string, data ...interface{}) {
+	if g.level >= gormlogger.Error {
+		g.l.With("source", gormSource()).Errorf(msg, data...)
+	}
+}
+
+// Trace 输出执行的SQL、影响的行数和耗时
+func (g *Logger) Trace(_ context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if g.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := g.now().Sub(begin)
+	fields := func() []interface{} {
+		sql, rows := fc()
+		return []interface{}{
+			"sql", sql,
+			"rows", rows,
+			"elapsed_ms", float64(elapsed) / float64(time.Millisecond),
+			"source", gormSource(),
+		}
+	}
+	switch {
+	case err != nil && g.level >= gormlogger.Error && !(g.IgnoreRecordNotFound && errors.Is(err, gormlogger.ErrRecordNotFound)):
+		g.l.Errorw("sql error", append(fields(), "error", err)...)
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold && g.level >= gormlogger.Warn:
+		g.l.Warnw("slow sql", append(fields(), "slow_threshold_ms", float64(g.slowThreshold)/float64(time.Millisecond))...)
+	case g.level >= gormlogger.Info:
+		g.l.Infow("sql", fields()...)
+	}
+}
+
+// gormSource 调用gorm的代码位置, 跳过本包和gorm内部的栈帧
+// gormSource只在Trace中调用, 栈上不会出现logs和zap的栈帧
+func gormSource() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		// 本包的测试代码不算在内
+		internal := strings.HasPrefix(frame.Function, "gorm.io/") ||
+			strings.HasPrefix(frame.Function, "github.com/xpfo-go/logs/gormlog.") && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
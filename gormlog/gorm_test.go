@@ -0,0 +1,109 @@
+package gormlog
+
+import (
+	"context"
+	"errors"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	gormlogger "gorm.io/gorm/logger"
+	"strings"
+	"testing"
+	"time"
+)
+
+// observe 使用临时目录初始化默认logger, 返回写入默认logger的日志
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", Level: "debug", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+	core, recorded := observer.New(zapcore.DebugLevel)
+	t.Cleanup(logs.AddCore(core))
+	return recorded
+}
+
+func TestTrace(t *testing.T) {
+	begin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		mode        gormlogger.LogLevel
+		elapsed     time.Duration
+		err         error
+		ignoreNF    bool
+		wantMsg     string
+		wantLevel   zapcore.Level
+		wantEntries int
+	}{
+		{"sql", gormlogger.Info, time.Millisecond, nil, true, "sql", zapcore.InfoLevel, 1},
+		{"slow", gormlogger.Info, time.Second, nil, true, "slow sql", zapcore.WarnLevel, 1},
+		{"error", gormlogger.Info, time.Millisecond, errors.New("syntax"), true, "sql error", zapcore.ErrorLevel, 1},
+		{"record not found ignored", gormlogger.Info, time.Millisecond, gormlogger.ErrRecordNotFound, true, "sql", zapcore.InfoLevel, 1},
+		{"record not found logged", gormlogger.Info, time.Millisecond, gormlogger.ErrRecordNotFound, false, "sql error", zapcore.ErrorLevel, 1},
+		{"warn mode skips sql", gormlogger.Warn, time.Millisecond, nil, true, "", 0, 0},
+		{"warn mode keeps slow", gormlogger.Warn, time.Second, nil, true, "slow sql", zapcore.WarnLevel, 1},
+		{"silent", gormlogger.Silent, time.Second, errors.New("syntax"), true, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			g := New(100 * time.Millisecond)
+			g.IgnoreRecordNotFound = tt.ignoreNF
+			g.now = func() time.Time { return begin.Add(tt.elapsed) }
+			lg := g.LogMode(tt.mode)
+			lg.Trace(context.Background(), begin, func() (string, int64) { return "SELECT 1", 3 }, tt.err)
+
+			entries := recorded.All()
+			if len(entries) != tt.wantEntries {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.wantEntries)
+			}
+			if tt.wantEntries == 0 {
+				return
+			}
+			e := entries[0]
+			fields := e.ContextMap()
+			if e.Message != tt.wantMsg || e.Level != tt.wantLevel {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.Message, tt.wantLevel, tt.wantMsg)
+			}
+			if fields["sql"] != "SELECT 1" || fields["rows"] != int64(3) || fields["system"] != "gorm" {
+				t.Errorf("fields = %v", fields)
+			}
+			if want := float64(tt.elapsed) / float64(time.Millisecond); fields["elapsed_ms"] != want {
+				t.Errorf("elapsed_ms = %v, want %v", fields["elapsed_ms"], want)
+			}
+			if src, _ := fields["source"].(string); !strings.Contains(src, "gorm_test.go:") {
+				t.Errorf("source = %q, want the calling test file", src)
+			}
+		})
+	}
+}
+
+func TestLogMode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode gormlogger.LogLevel
+		want []string
+	}{
+		{"info", gormlogger.Info, []string{"info 1", "warn 2", "error 3"}},
+		{"warn", gormlogger.Warn, []string{"warn 2", "error 3"}},
+		{"error", gormlogger.Error, []string{"error 3"}},
+		{"silent", gormlogger.Silent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			lg := New(0).LogMode(tt.mode)
+			ctx := context.Background()
+			lg.Info(ctx, "info %d", 1)
+			lg.Warn(ctx, "warn %d", 2)
+			lg.Error(ctx, "error %d", 3)
+			var got []string
+			for _, e := range recorded.All() {
+				got = append(got, e.Message)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
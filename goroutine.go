@@ -0,0 +1,71 @@
+package logs
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// WithGoroutine 获取附加当前goroutine id(goid)和序号(gseq)的logger, 用于从交错的日志中还原每个goroutine的执行顺序
+// gseq从1开始, 每输出一条日志加1, 应在goroutine开始时调用一次并在该goroutine中一直使用返回的logger
+//
+//	go func() {
+//		log := logs.WithGoroutine()
+//		log.Infow("start", "job", id)
+//	}()
+func WithGoroutine() *zap.SugaredLogger {
+	var seq uint64
+	return userLogger().Desugar().WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return gseqCore{c, &seq}
+	})).With(zap.Uint64("goid", goroutineID())).Sugar()
+}
+
+// gseqCore 为实际输出的日志附加递增的gseq字段
+type gseqCore struct {
+	zapcore.Core
+	seq *uint64
+}
+
+func (c gseqCore) With(fields []zapcore.Field) zapcore.Core {
+	return gseqCore{c.Core.With(fields), c.seq}
+}
+
+// Check 内部core会输出时才附加gseq, 被级别或模块过滤的日志不占用序号
+func (c gseqCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	inner := c.Core.Check(ent, nil)
+	if inner == nil {
+		return ce
+	}
+	inner.ErrorOutput = zapcore.Lock(os.Stderr)
+	return ce.AddCore(ent, gseqWriter{c.Core, inner, c.seq})
+}
+
+// gseqWriter 写入时为内部core检查过的日志附加gseq字段
+type gseqWriter struct {
+	zapcore.Core
+	inner *zapcore.CheckedEntry
+	seq   *uint64
+}
+
+func (w gseqWriter) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// 检查内部core时还没有调用位置和调用栈, 使用写入时的条目
+	w.inner.Entry = ent
+	w.inner.Write(append(fields[:len(fields):len(fields)], zap.Uint64("gseq", atomic.AddUint64(w.seq, 1)))...)
+	return nil
+}
+
+// goroutineID 从调用栈信息 "goroutine 123 [running]:" 中解析当前goroutine的id
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	b := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
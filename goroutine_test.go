@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithGoroutine(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+		// want 每条写入的日志的gseq
+		want []uint64
+	}{
+		{"sequence", func() {
+			log := WithGoroutine()
+			log.Info("g line")
+			log.Warn("g line")
+			log.Error("g line")
+		}, []uint64{1, 2, 3}},
+		{"level filtered", func() {
+			log := WithGoroutine()
+			log.Debug("g line")
+			log.Info("g line")
+			log.Debug("g line")
+			log.Info("g line")
+		}, []uint64{1, 2}},
+		{"module filtered", func() {
+			if err := SetNamedLevel("gseq", "warn"); err != nil {
+				t.Fatal(err)
+			}
+			log := WithGoroutine().Named("gseq")
+			log.Info("g line")
+			log.Warn("g line")
+			log.Info("g line")
+			log.Warn("g line")
+		}, []uint64{1, 2}},
+		{"derived loggers share the sequence", func() {
+			log := WithGoroutine()
+			log.Info("g line")
+			log.With("k", "v").Info("g line")
+			log.Named("child").Info("g line")
+		}, []uint64{1, 2, 3}},
+		{"separate loggers", func() {
+			a, b := WithGoroutine(), WithGoroutine()
+			a.Info("g line")
+			b.Info("g line")
+			a.Info("g line")
+		}, []uint64{1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "info")
+			tt.log()
+
+			entries := recorded.FilterMessage("g line").All()
+			if len(entries) != len(tt.want) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.want))
+			}
+			goid := goroutineID()
+			for i, e := range entries {
+				fields := e.ContextMap()
+				if got := fields["gseq"]; got != tt.want[i] {
+					t.Errorf("entry %d gseq = %v, want %d", i, got, tt.want[i])
+				}
+				if got := fields["goid"]; got != goid {
+					t.Errorf("entry %d goid = %v, want %d", i, got, goid)
+				}
+				if !strings.HasSuffix(e.Caller.File, "goroutine_test.go") {
+					t.Errorf("caller = %s, want goroutine_test.go", e.Caller.File)
+				}
+			}
+		})
+	}
+}
+
+func TestWithGoroutineConcurrent(t *testing.T) {
+	recorded := observeDefault(t, "info")
+	const n, lines = 20, 5
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log := WithGoroutine()
+			for j := 0; j < lines; j++ {
+				log.Info("g line")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 每个goroutine的gseq从1开始连续递增
+	seqs := make(map[uint64][]uint64)
+	for _, e := range recorded.FilterMessage("g line").All() {
+		fields := e.ContextMap()
+		goid := fields["goid"].(uint64)
+		seqs[goid] = append(seqs[goid], fields["gseq"].(uint64))
+	}
+	if len(seqs) != n {
+		t.Fatalf("goroutines = %d, want %d", len(seqs), n)
+	}
+	for goid, s := range seqs {
+		for i, seq := range s {
+			if seq != uint64(i+1) {
+				t.Errorf("goroutine %d gseq = %v, want 1..%d", goid, s, lines)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package logs
+
+import "go.uber.org/zap"
+
+// GRPCLogger 写入默认logger的grpc日志, 实现grpclog.LoggerV2, 日志附加字段system=grpc
+// 不依赖grpc, 通过grpclog.SetLoggerV2(logs.NewGRPCLoggerV2(0))使用
+type GRPCLogger struct {
+	l         *zap.SugaredLogger
+	verbosity int
+}
+
+// NewGRPCLoggerV2 获取写入默认logger的grpc日志, 重新初始化后依然有效
+// verbosity为0时不输出grpc的Info日志, 大于0时输出Info日志, V(l)在l不大于verbosity时为true
+// Fatal与包级函数Fatal相同, 输出日志后退出进程
+func NewGRPCLoggerV2(verbosity int) *GRPCLogger {
+	return &GRPCLogger{
+		l:         Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar().With("system", "grpc"),
+		verbosity: verbosity,
+	}
+}
+
+func (g *GRPCLogger) Info(args ...interface{}) {
+	if g.verbosity > 0 {
+		g.l.Info(args...)
+	}
+}
+
+func (g *GRPCLogger) Infoln(args ...interface{}) {
+	if g.verbosity > 0 {
+		g.l.Infoln(args...)
+	}
+}
+
+func (g *GRPCLogger) Infof(format string, args ...interface{}) {
+	if g.verbosity > 0 {
+		g.l.Infof(format, args...)
+	}
+}
+
+func (g *GRPCLogger) Warning(args ...interface{}) {
+	g.l.Warn(args...)
+}
+
+func (g *GRPCLogger) Warningln(args ...interface{}) {
+	g.l.Warnln(args...)
+}
+
+func (g *GRPCLogger) Warningf(format string, args ...interface{}) {
+	g.l.Warnf(format, args...)
+}
+
+func (g *GRPCLogger) Error(args ...interface{}) {
+	g.l.Error(args...)
+}
+
+func (g *GRPCLogger) Errorln(args ...interface{}) {
+	g.l.Errorln(args...)
+}
+
+func (g *GRPCLogger) Errorf(format string, args ...interface{}) {
+	g.l.Errorf(format, args...)
+}
+
+func (g *GRPCLogger) Fatal(args ...interface{}) {
+	g.l.Fatal(args...)
+}
+
+func (g *GRPCLogger) Fatalln(args ...interface{}) {
+	g.l.Fatalln(args...)
+}
+
+func (g *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	g.l.Fatalf(format, args...)
+}
+
+// V 日志详细级别l是否开启
+func (g *GRPCLogger) V(l int) bool {
+	return l <= g.verbosity
+}
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGRPCLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		log       func(g *GRPCLogger)
+		want      zapcore.Level
+		wantMsg   string
+	}{
+		{"Info verbosity 0", 0, func(g *GRPCLogger) { g.Info("grpc", "line") }, 0, ""},
+		{"Infof verbosity 0", 0, func(g *GRPCLogger) { g.Infof("grpc %s", "line") }, 0, ""},
+		{"Info", 1, func(g *GRPCLogger) { g.Info("grpc", "line") }, zapcore.InfoLevel, "grpcline"},
+		{"Infoln", 1, func(g *GRPCLogger) { g.Infoln("grpc", "line") }, zapcore.InfoLevel, "grpc line"},
+		{"Infof", 1, func(g *GRPCLogger) { g.Infof("grpc %s", "line") }, zapcore.InfoLevel, "grpc line"},
+		{"Warning", 0, func(g *GRPCLogger) { g.Warning("grpc line") }, zapcore.WarnLevel, "grpc line"},
+		{"Warningln", 0, func(g *GRPCLogger) { g.Warningln("grpc", "line") }, zapcore.WarnLevel, "grpc line"},
+		{"Warningf", 0, func(g *GRPCLogger) { g.Warningf("grpc %d", 1) }, zapcore.WarnLevel, "grpc 1"},
+		{"Error", 0, func(g *GRPCLogger) { g.Error("grpc line") }, zapcore.ErrorLevel, "grpc line"},
+		{"Errorln", 0, func(g *GRPCLogger) { g.Errorln("grpc", "line") }, zapcore.ErrorLevel, "grpc line"},
+		{"Errorf", 0, func(g *GRPCLogger) { g.Errorf("grpc %v", errors.New("boom")) }, zapcore.ErrorLevel, "grpc boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			tt.log(NewGRPCLoggerV2(tt.verbosity))
+
+			entries := recorded.All()
+			if tt.wantMsg == "" {
+				if len(entries) != 0 {
+					t.Errorf("entries = %d, want 0", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.Message != tt.wantMsg {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.Message, tt.want, tt.wantMsg)
+			}
+			if got := e.ContextMap()["system"]; got != "grpc" {
+				t.Errorf("system = %v, want grpc", got)
+			}
+			if !strings.HasSuffix(e.Caller.File, "grpc_test.go") {
+				t.Errorf("caller = %s, want grpc_test.go", e.Caller.File)
+			}
+		})
+	}
+}
+
+func TestGRPCLoggerV(t *testing.T) {
+	tests := []struct {
+		verbosity int
+		l         int
+		want      bool
+	}{
+		{0, 0, true},
+		{0, 1, false},
+		{2, 2, true},
+		{2, 3, false},
+	}
+	for _, tt := range tests {
+		if got := NewGRPCLoggerV2(tt.verbosity).V(tt.l); got != tt.want {
+			t.Errorf("NewGRPCLoggerV2(%d).V(%d) = %v, want %v", tt.verbosity, tt.l, got, tt.want)
+		}
+	}
+}
+
+func TestGRPCLoggerFollowsReinit(t *testing.T) {
+	// 在初始化之前创建, 重新初始化后依然写入默认logger, 并使用新的日志级别
+	g := NewGRPCLoggerV2(1)
+	recorded := observeDefault(t, "warn")
+	g.Info("filtered")
+	g.Warning("written")
+
+	entries := recorded.All()
+	if len(entries) != 1 || entries[0].Message != "written" {
+		t.Errorf("entries = %v, want only the warning", entries)
+	}
+}
+
+func TestGRPCLoggerConcurrent(t *testing.T) {
+	recorded := observeDefault(t, "debug")
+	g := NewGRPCLoggerV2(1)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g.Infof("grpc %d", i)
+		}(i)
+	}
+	wg.Wait()
+	if got := recorded.FilterField(String("system", "grpc")).Len(); got != n {
+		t.Errorf("entries = %d, want %d", got, n)
+	}
+}
+
+func TestGRPCLoggerFatal(t *testing.T) {
+	tests := []struct {
+		name  string
+		fatal func(g *GRPCLogger)
+		want  string
+	}{
+		{"Fatal", func(g *GRPCLogger) { g.Fatal("grpc ", "fatal") }, "grpc fatal"},
+		{"Fatalln", func(g *GRPCLogger) { g.Fatalln("grpc", "fatal") }, "grpc fatal"},
+		{"Fatalf", func(g *GRPCLogger) { g.Fatalf("grpc %s", "fatal") }, "grpc fatal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if os.Getenv(childEnv) == "1" {
+				c := LogConfig{Dir: ".", FileName: "test", Encoding: "json", DisableConsole: true, MainBuffered: true, StacktraceLevel: "disabled"}
+				if err := InitLogSetting(&c); err != nil {
+					t.Fatal(err)
+				}
+				tt.fatal(NewGRPCLoggerV2(0))
+				return
+			}
+
+			dir := t.TempDir()
+			out, err := runChild(t, dir)
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("child exit = %v, want exit status 1\n%s", err, out)
+			}
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			for _, want := range []string{`"level":"FATAL"`, `"msg":"` + tt.want + `"`, `"system":"grpc"`} {
+				if !strings.Contains(got, want) {
+					t.Errorf("log = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader 传递请求id的header
+const RequestIDHeader = "X-Request-ID"
+
+// HTTPMiddleware 输出请求日志的net/http中间件
+// 请求id取自X-Request-ID, 不存在时生成, 同时写入响应的X-Request-ID
+// 附加了request_id字段的logger保存在请求的context中, handler中通过Ctx获取
+// 每个请求结束后输出一条日志, 字段为request_id method path status size duration, 状态码4xx以warn级别输出, 5xx以error级别输出
+//
+//	http.ListenAndServe(addr, logs.HTTPMiddleware(mux))
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		logger := userLogger().With("request_id", id)
+		rw := &responseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), logger)))
+
+		fields := []interface{}{
+			"method", r.Method,
+			"path", r.URL.RequestURI(),
+			"status", rw.statusCode(),
+			"size", rw.size,
+			"duration", time.Since(start),
+		}
+		if rw.hijacked {
+			fields = append(fields, "hijacked", true)
+		}
+		// 调用位置为中间件内部, 不输出调用位置
+		logger = logger.Desugar().WithOptions(zap.WithCaller(false)).Sugar()
+		switch status := rw.statusCode(); {
+		case status >= http.StatusInternalServerError:
+			logger.Errorw("request", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warnw("request", fields...)
+		default:
+			logger.Infow("request", fields...)
+		}
+	})
+}
+
+// Ctx 获取context中保存的logger, 与FromContext相同, 不存在时返回全局logger
+//
+//	logs.Ctx(r.Context()).Infow("order created", "order", id)
+func Ctx(ctx context.Context) *zap.SugaredLogger {
+	return FromContext(ctx)
+}
+
+// newRequestID 生成16字节随机数的十六进制请求id
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// responseWriter 记录状态码和写入字节数的http.ResponseWriter, 支持Flusher和Hijacker
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	size     int
+	hijacked bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("logs: ResponseWriter does not implement http.Hijacker")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap 返回原始的ResponseWriter, 供http.ResponseController使用
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode 响应的状态码, 连接被接管时为101, 未写入时为200
+func (w *responseWriter) statusCode() int {
+	switch {
+	case w.status != 0:
+		return w.status
+	case w.hijacked:
+		return http.StatusSwitchingProtocols
+	}
+	return http.StatusOK
+}
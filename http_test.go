@@ -0,0 +1,155 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// hijackRecorder 支持Hijack的ResponseRecorder
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	c1, c2 := net.Pipe()
+	_ = c2.Close()
+	return c1, bufio.NewReadWriter(bufio.NewReader(c1), bufio.NewWriter(c1)), nil
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		handler   http.HandlerFunc
+		hijack    bool
+		want      zapcore.Level
+		status    int
+		size      int
+	}{
+		{"ok", "", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("hello")) }, false, zapcore.InfoLevel, 200, 5},
+		{"request id header", "req-1", func(w http.ResponseWriter, r *http.Request) {}, false, zapcore.InfoLevel, 200, 0},
+		{"not found", "", http.NotFound, false, zapcore.WarnLevel, 404, 19},
+		{"server error", "", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(500) }, false, zapcore.ErrorLevel, 500, 0},
+		{"first WriteHeader wins", "", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, false, zapcore.InfoLevel, 201, 0},
+		{"flush", "", func(w http.ResponseWriter, r *http.Request) { w.(http.Flusher).Flush() }, false, zapcore.InfoLevel, 200, 0},
+		{"hijack unsupported", "", func(w http.ResponseWriter, r *http.Request) {
+			if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				t.Error("Hijack succeeded on a ResponseWriter without http.Hijacker")
+			}
+		}, false, zapcore.InfoLevel, 200, 0},
+		{"hijacked", "", func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = conn.Close()
+		}, true, zapcore.InfoLevel, 101, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			var ctxID interface{}
+			h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Ctx(r.Context()).Info("inside")
+				tt.handler(w, r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/orders?id=1", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			var w http.ResponseWriter = rec
+			if tt.hijack {
+				w = hijackRecorder{rec}
+			}
+			h.ServeHTTP(w, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.requestID != "" && id != tt.requestID || tt.requestID == "" && len(id) != 32 {
+				t.Errorf("response request id = %q, want %q or a generated id", id, tt.requestID)
+			}
+			if inside := recorded.FilterMessage("inside").All(); len(inside) == 1 {
+				ctxID = inside[0].ContextMap()["request_id"]
+			}
+			if ctxID != id {
+				t.Errorf("Ctx request_id = %v, want %s", ctxID, id)
+			}
+
+			entries := recorded.FilterMessage("request").All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.Caller.Defined {
+				t.Errorf("entry = %s caller %v, want %s without caller", e.Level, e.Caller, tt.want)
+			}
+			fields := e.ContextMap()
+			for k, want := range map[string]interface{}{
+				"request_id": id,
+				"method":     "GET",
+				"path":       "/orders?id=1",
+				"status":     int64(tt.status),
+				"size":       int64(tt.size),
+			} {
+				if fields[k] != want {
+					t.Errorf("%s = %v (%T), want %v", k, fields[k], fields[k], want)
+				}
+			}
+			if _, ok := fields["hijacked"]; ok != tt.hijack {
+				t.Errorf("hijacked field present = %v, want %v", ok, tt.hijack)
+			}
+		})
+	}
+}
+
+func TestHTTPMiddlewareConcurrent(t *testing.T) {
+	recorded := observeDefault(t, "debug")
+	srv := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ctx(r.Context()).Infow("inside", "path", r.URL.Path)
+		_, _ = fmt.Fprint(w, r.URL.Path)
+	})))
+	defer srv.Close()
+
+	const n = 30
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := http.Get(fmt.Sprintf("%s/%d", srv.URL, i))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_ = resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+	srv.Close()
+
+	// 同一请求的日志使用相同的request_id, 不同请求的request_id不同
+	ids := make(map[interface{}]interface{})
+	for _, e := range recorded.FilterMessage("inside").All() {
+		fields := e.ContextMap()
+		ids[fields["request_id"]] = fields["path"]
+	}
+	if len(ids) != n {
+		t.Fatalf("request ids = %d, want %d", len(ids), n)
+	}
+	for _, e := range recorded.FilterMessage("request").All() {
+		fields := e.ContextMap()
+		if ids[fields["request_id"]] != fields["path"] {
+			t.Errorf("request %v logged path %v, inside logged %v", fields["request_id"], fields["path"], ids[fields["request_id"]])
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package logs
+
+// LeveledLogger 常见框架依赖注入时使用的分级日志接口
+type LeveledLogger interface {
+	Debug(v ...interface{})
+	Debugf(format string, v ...interface{})
+	Info(v ...interface{})
+	Infof(format string, v ...interface{})
+	Warn(v ...interface{})
+	Warnf(format string, v ...interface{})
+	Error(v ...interface{})
+	Errorf(format string, v ...interface{})
+}
+
+// AsInterface 获取实现LeveledLogger的值, 调用会转发到包级函数, 重新初始化后依然有效
+func AsInterface() LeveledLogger {
+	return pkgLogger{}
+}
+
+type pkgLogger struct{}
+
+func (pkgLogger) Debug(v ...interface{}) {
+	l().Debug(v...)
+}
+
+func (pkgLogger) Debugf(format string, v ...interface{}) {
+	l().Debugf(format, v...)
+}
+
+func (pkgLogger) Info(v ...interface{}) {
+	l().Info(v...)
+}
+
+func (pkgLogger) Infof(format string, v ...interface{}) {
+	l().Infof(format, v...)
+}
+
+func (pkgLogger) Warn(v ...interface{}) {
+	l().Warn(v...)
+}
+
+func (pkgLogger) Warnf(format string, v ...interface{}) {
+	l().Warnf(format, v...)
+}
+
+func (pkgLogger) Error(v ...interface{}) {
+	l().Error(v...)
+}
+
+func (pkgLogger) Errorf(format string, v ...interface{}) {
+	l().Errorf(format, v...)
+}
@@ -0,0 +1,56 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"testing"
+)
+
+func TestAsInterface(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     func(l LeveledLogger)
+		want    zapcore.Level
+		wantMsg string
+	}{
+		{"Debug", func(l LeveledLogger) { l.Debug("iface ", 1) }, zapcore.DebugLevel, "iface 1"},
+		{"Debugf", func(l LeveledLogger) { l.Debugf("iface %d", 1) }, zapcore.DebugLevel, "iface 1"},
+		{"Info", func(l LeveledLogger) { l.Info("iface ", 1) }, zapcore.InfoLevel, "iface 1"},
+		{"Infof", func(l LeveledLogger) { l.Infof("iface %d", 1) }, zapcore.InfoLevel, "iface 1"},
+		{"Warn", func(l LeveledLogger) { l.Warn("iface ", 1) }, zapcore.WarnLevel, "iface 1"},
+		{"Warnf", func(l LeveledLogger) { l.Warnf("iface %d", 1) }, zapcore.WarnLevel, "iface 1"},
+		{"Error", func(l LeveledLogger) { l.Error("iface ", 1) }, zapcore.ErrorLevel, "iface 1"},
+		{"Errorf", func(l LeveledLogger) { l.Errorf("iface %d", 1) }, zapcore.ErrorLevel, "iface 1"},
+	}
+	// 在初始化之前获取, 重新初始化后依然写入默认logger
+	l := AsInterface()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			tt.log(l)
+
+			entries := recorded.All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.Message != tt.wantMsg {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.Message, tt.want, tt.wantMsg)
+			}
+			if !strings.HasSuffix(e.Caller.File, "interface_test.go") {
+				t.Errorf("caller = %s, want interface_test.go", e.Caller.File)
+			}
+		})
+	}
+}
+
+func TestAsInterfaceLevel(t *testing.T) {
+	recorded := observeDefault(t, "warn")
+	l := AsInterface()
+	l.Debug("filtered")
+	l.Info("filtered")
+	l.Warn("written")
+	if got := recorded.Len(); got != 1 {
+		t.Errorf("entries = %d, want 1 at warn level", got)
+	}
+}
@@ -0,0 +1,154 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+)
+
+// traceLevel 低于debug的trace级别, 用于输出请求内容等大量的调试信息
+const traceLevel = zapcore.DebugLevel - 1
+
+// TraceLevel trace级别, 供子包等在zapcore.Level上使用
+const TraceLevel = traceLevel
+
+// ParseLevel 解析日志级别, 在zapcore.Level的基础上支持trace, 无法识别时返回包含可选值的错误
+func ParseLevel(text string) (zapcore.Level, error) {
+	lvl, err := parseLevel(text)
+	if err != nil {
+		return lvl, fmt.Errorf("logs: invalid level %q, accepted values: %s", text, levelNames)
+	}
+	return lvl, nil
+}
+
+// parseLevel 解析日志级别, 在zapcore.Level的基础上支持trace
+func parseLevel(text string) (zapcore.Level, error) {
+	if strings.EqualFold(text, "trace") {
+		return traceLevel, nil
+	}
+	var lvl zapcore.Level
+	err := lvl.UnmarshalText([]byte(text))
+	return lvl, err
+}
+
+// levelString 日志级别的名称, trace级别为trace
+func levelString(lvl zapcore.Level) string {
+	if lvl == traceLevel {
+		return "trace"
+	}
+	return lvl.String()
+}
+
+// traceLevelEncoder trace级别输出TRACE, color不为空时使用该颜色, 其他级别使用enc
+func traceLevelEncoder(enc zapcore.LevelEncoder, color string) zapcore.LevelEncoder {
+	label := "TRACE"
+	if color != "" {
+		label = "\x1b[" + color + "m" + label + "\x1b[0m"
+	}
+	return func(lvl zapcore.Level, pae zapcore.PrimitiveArrayEncoder) {
+		if lvl == traceLevel {
+			pae.AppendString(label)
+			return
+		}
+		enc(lvl, pae)
+	}
+}
+
+// SetLevel 在运行时修改日志级别, 立即对所有输出生效, 无需重新初始化
+// 控制台和日志文件单独设置的级别同时被取消
+func SetLevel(level string) error {
+	return SetOutputLevel("all", level)
+}
+
+// SetOutputLevel 在运行时修改指定输出的日志级别, target为console file all
+// console和file只修改对应输出的级别, 另一个输出的级别保持不变, all与SetLevel相同
+//
+//	logs.SetOutputLevel("console", "warn")
+func SetOutputLevel(target, level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return fmt.Errorf("logs: invalid level %q, accepted values: %s", level, levelNames)
+	}
+	o := std().outputs
+	// 输出实际生效的级别为总级别和该输出级别中较高的一个
+	console := maxLevel(atomicLevel.Level(), o.consoleLevel.Level())
+	file := maxLevel(atomicLevel.Level(), o.fileLevel.Level())
+	switch target {
+	case "all":
+		o.consoleLevel.SetLevel(traceLevel)
+		o.fileLevel.SetLevel(traceLevel)
+		atomicLevel.SetLevel(lvl)
+		return nil
+	case "console":
+		console = lvl
+	case "file":
+		file = lvl
+	default:
+		return fmt.Errorf("logs: invalid target %q, accepted values: console file all", target)
+	}
+	o.consoleLevel.SetLevel(console)
+	o.fileLevel.SetLevel(file)
+	atomicLevel.SetLevel(minLevel(console, file))
+	return nil
+}
+
+func minLevel(a, b zapcore.Level) zapcore.Level {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxLevel(a, b zapcore.Level) zapcore.Level {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// GetLevel 获取当前的日志级别
+func GetLevel() string {
+	return levelString(atomicLevel.Level())
+}
+
+// LevelHandler 查看和修改日志级别的http handler, 修改与SetLevel相同, 对所有输出生效
+// GET返回当前级别 {"level":"info"}, PUT {"level":"debug"}修改级别, 无效的级别返回400
+//
+//	mux.Handle("/admin/log/level", logs.LevelHandler())
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevel)
+}
+
+// levelPayload LevelHandler的请求和响应内容
+type levelPayload struct {
+	Level string `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// serveLevel 与zap.AtomicLevel的handler相同的接口, 修改级别时通过SetLevel同时修改控制台和日志文件的级别
+func serveLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(levelPayload{Error: "malformed request body: " + err.Error()})
+			return
+		}
+		if err := SetLevel(req.Level); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(levelPayload{Error: err.Error()})
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(levelPayload{Error: "only GET and PUT are supported"})
+		return
+	}
+	_ = enc.Encode(levelPayload{Level: GetLevel()})
+}
@@ -0,0 +1,261 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	tests := []struct {
+		level     string
+		wantLevel string
+		want      []string
+		wantErr   bool
+	}{
+		{"trace", "trace", []string{"trace", "debug", "info", "warn", "error"}, false},
+		{"debug", "debug", []string{"debug", "info", "warn", "error"}, false},
+		{"WARN", "warn", []string{"warn", "error"}, false},
+		{"error", "error", []string{"error"}, false},
+		// 无效的级别不修改当前级别
+		{"verbose", "info", []string{"info", "warn", "error"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			// 修改级别之前创建的logger同样使用新的级别
+			derived := []*Logger{Named("db"), With("k", "v"), WithFields(map[string]interface{}{"a": 1})}
+			err := SetLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetLevel error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := GetLevel(); got != tt.wantLevel {
+				t.Errorf("GetLevel = %q, want %q", got, tt.wantLevel)
+			}
+
+			loggers := append([]*Logger{live()}, derived...)
+			for i, lg := range loggers {
+				lg.Tracef("trace %d", i)
+				lg.Debugf("debug %d", i)
+				lg.Infof("info %d", i)
+				lg.Warnf("warn %d", i)
+				lg.Errorf("error %d", i)
+			}
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			for i := range loggers {
+				for _, name := range []string{"trace", "debug", "info", "warn", "error"} {
+					line := fmt.Sprintf("%s %d", name, i)
+					if want := contains(tt.want, name); strings.Contains(got, line) != want {
+						t.Errorf("%q logged = %v, want %v", line, !want, want)
+					}
+				}
+			}
+			if errLog := readLog(t, filepath.Join(dir, "test_err.log")); !strings.Contains(errLog, "error 0") {
+				t.Errorf("error file = %q, want the error entry", errLog)
+			}
+		})
+	}
+}
+
+func TestSetLevelDoesNotAffectNewLogger(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info"})
+	dir := t.TempDir()
+	lg, err := NewLogger(&LogConfig{Dir: dir, FileName: "own", Level: "info", DisableConsole: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lg.Close()
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	lg.Debug("own debug")
+	_ = lg.Sync()
+	if got := readLog(t, filepath.Join(dir, "own.log")); strings.Contains(got, "own debug") {
+		t.Errorf("NewLogger followed SetLevel: %q", got)
+	}
+}
+
+func TestSetLevelConcurrent(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info"})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				Debug("concurrent")
+				Named("db").Info("concurrent")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				_ = SetLevel([]string{"debug", "info", "warn"}[(i+j)%3])
+				_ = GetLevel()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantLevel  string
+	}{
+		{"get", http.MethodGet, "", http.StatusOK, `{"level":"info"}`, "info"},
+		{"put debug", http.MethodPut, `{"level":"debug"}`, http.StatusOK, `{"level":"debug"}`, "debug"},
+		{"put trace", http.MethodPut, `{"level":"trace"}`, http.StatusOK, `{"level":"trace"}`, "trace"},
+		{"put unknown", http.MethodPut, `{"level":"verbose"}`, http.StatusBadRequest, "", "info"},
+		{"put malformed", http.MethodPut, `level`, http.StatusBadRequest, "", "info"},
+		{"post", http.MethodPost, `{"level":"debug"}`, http.StatusMethodNotAllowed, "", "info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", FileLevel: "info"})
+			// 控制台和日志文件单独设置的级别被同时修改
+			if err := SetOutputLevel("console", "warn"); err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			LevelHandler().ServeHTTP(rec, httptest.NewRequest(tt.method, "/level", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+				t.Errorf("body = %s, want %s", rec.Body, tt.wantBody)
+			}
+			if got := GetLevel(); got != tt.wantLevel {
+				t.Errorf("GetLevel = %q, want %q", got, tt.wantLevel)
+			}
+			Debug("debug line")
+			_ = Sync()
+			logged := strings.Contains(readLog(t, filepath.Join(dir, "test.log")), "debug line")
+			if want := tt.wantLevel == "debug" || tt.wantLevel == "trace"; logged != want {
+				t.Errorf("debug line in file = %v, want %v", logged, want)
+			}
+			if o := std().outputs; tt.wantStatus == http.StatusOK && tt.method == http.MethodPut && o.consoleLevel.Level() > atomicLevel.Level() {
+				t.Errorf("console level = %s, want it to follow the handler", o.consoleLevel.Level())
+			}
+		})
+	}
+}
+
+func TestOutputLevels(t *testing.T) {
+	tests := []struct {
+		name        string
+		conf        LogConfig
+		target      string
+		level       string
+		wantFile    bool
+		wantConsole bool
+	}{
+		{"file debug console info", LogConfig{Level: "info", FileLevel: "debug", ConsoleLevel: "info"}, "", "", true, false},
+		{"console debug file info", LogConfig{Level: "info", FileLevel: "info", ConsoleLevel: "debug"}, "", "", false, true},
+		{"fall back to Level", LogConfig{Level: "debug"}, "", "", true, true},
+		{"set console", LogConfig{Level: "info"}, "console", "debug", false, true},
+		{"set file", LogConfig{Level: "info"}, "file", "debug", true, false},
+		{"set all", LogConfig{Level: "info", FileLevel: "warn", ConsoleLevel: "warn"}, "all", "debug", true, true},
+		{"raise file", LogConfig{Level: "debug"}, "file", "warn", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			stdout, _ := initConsoleLogger(t, &c)
+			if tt.target != "" {
+				if err := SetOutputLevel(tt.target, tt.level); err != nil {
+					t.Fatal(err)
+				}
+			}
+			Debug("debug line")
+			_ = Sync()
+
+			if got := strings.Contains(readLog(t, filepath.Join(c.Dir, "test.log")), "debug line"); got != tt.wantFile {
+				t.Errorf("debug in file = %v, want %v", got, tt.wantFile)
+			}
+			if got := strings.Contains(stdout(), "debug line"); got != tt.wantConsole {
+				t.Errorf("debug on stdout = %v, want %v", got, tt.wantConsole)
+			}
+		})
+	}
+	if err := SetOutputLevel("syslog", "debug"); err == nil || !strings.Contains(err.Error(), "invalid target") {
+		t.Errorf("SetOutputLevel unknown target error = %v", err)
+	}
+}
+
+func TestTraceLevelEncoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		lvl   zapcore.Level
+		color string
+		want  string
+	}{
+		{"trace", TraceLevel, "", "TRACE"},
+		{"trace colored", TraceLevel, "35", "\x1b[35mTRACE\x1b[0m"},
+		{"debug unchanged", zapcore.DebugLevel, "35", "DEBUG"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
+				LevelKey:    "level",
+				EncodeLevel: traceLevelEncoder(zapcore.CapitalLevelEncoder, tt.color),
+			})
+			buf, err := enc.EncodeEntry(zapcore.Entry{Level: tt.lvl}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Errorf("level = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		encoding string
+		want     string
+	}{
+		{"console", "trace", "", "\tTRACE\t"},
+		{"json", "trace", "json", `"level":"TRACE"`},
+		{"debug disables trace", "debug", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: tt.level, Encoding: tt.encoding})
+			Trace("trace line")
+			Tracef("trace %s", "formatted")
+			Tracew("trace fields", "k", "v")
+			_ = Sync()
+
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			if tt.want == "" {
+				if strings.Contains(got, "TRACE") {
+					t.Errorf("log = %q, want no trace entries", got)
+				}
+				// debug及以上级别时trace不会创建日志
+				if ce := l().Desugar().Check(TraceLevel, "x"); ce != nil {
+					t.Error("trace entry checked at debug level")
+				}
+				return
+			}
+			if n := strings.Count(got, tt.want); n != 3 {
+				t.Errorf("log = %q, want 3 entries with %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync/atomic"
+)
+
+// Desugar 获取默认logger对应的*zap.Logger, 可交给需要zap类型的库使用
+// 返回的logger写入时转发到当前的默认logger, 重新初始化后使用新的输出和日志级别, 无需重新获取
+// 调用位置、调用栈和Development等选项使用获取时的配置
+func Desugar() *zap.Logger {
+	return zap.New(liveCore{}, std().zapOptions...)
+}
+
+// Sugar 获取默认logger对应的*zap.SugaredLogger, 与Desugar相同, 重新初始化后无需重新获取
+func Sugar() *zap.SugaredLogger {
+	return Desugar().Sugar()
+}
+
+// live 获取写入时转发到当前默认logger的Logger, 包级函数派生的Logger使用, 重新初始化后使用新的输出
+// 没有自己的输出, Close和Rotate作用于当前的默认Logger
+func live() *Logger {
+	// 与默认logger相同, Logger的方法多一层调用
+	opts := append([]zap.Option{zap.AddCallerSkip(1)}, std().zapOptions...)
+	return &Logger{l: zap.New(liveCore{}, opts...).Sugar()}
+}
+
+// liveCore 每次写入时转发到当前默认logger的core
+type liveCore struct {
+	fields []zapcore.Field
+	// with 缓存附加了fields的core, 默认logger替换后重新生成
+	with *atomic.Value
+}
+
+// liveCoreWith 附加了字段的core及生成时默认logger的gen
+type liveCoreWith struct {
+	gen  uint64
+	core zapcore.Core
+}
+
+func (c liveCore) core() zapcore.Core {
+	if len(c.fields) == 0 {
+		return lz().Core()
+	}
+	cur := defaultLogger.Load().(pkgLoggers)
+	if cached, _ := c.with.Load().(*liveCoreWith); cached != nil && cached.gen == cur.gen {
+		return cached.core
+	}
+	core := cur.base.Core().With(c.fields)
+	c.with.Store(&liveCoreWith{gen: cur.gen, core: core})
+	return core
+}
+
+func (c liveCore) Enabled(lvl zapcore.Level) bool {
+	return lz().Core().Enabled(lvl)
+}
+
+func (c liveCore) With(fields []zapcore.Field) zapcore.Core {
+	return liveCore{fields: append(c.fields[:len(c.fields):len(c.fields)], fields...), with: new(atomic.Value)}
+}
+
+func (c liveCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	return c.core().Check(ent, ce)
+}
+
+func (c liveCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().Write(ent, fields)
+}
+
+func (c liveCore) Sync() error {
+	return lz().Core().Sync()
+}
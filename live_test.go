@@ -0,0 +1,106 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDesugarFollowsReinit(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(msg string)
+	}{
+		{"Desugar", func() func(string) {
+			zl := Desugar()
+			return func(msg string) { zl.Info(msg) }
+		}()},
+		{"Desugar.With", func() func(string) {
+			zl := Desugar().With(String("k", "v"))
+			return func(msg string) { zl.Info(msg) }
+		}()},
+		{"Sugar", func() func(string) {
+			s := Sugar()
+			return func(msg string) { s.Infow(msg, "k", "v") }
+		}()},
+		{"Sugar.Named", func() func(string) {
+			s := Sugar().Named("db")
+			return func(msg string) { s.Info(msg) }
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 获取之后重新初始化, 写入新的日志文件
+			initTestLogger(t, &LogConfig{Level: "info"})
+			dir := t.TempDir()
+			if err := InitLogSetting(&LogConfig{Dir: dir, FileName: "after", Level: "info", DisableConsole: true}); err != nil {
+				t.Fatal(err)
+			}
+			tt.write("live line")
+			_ = Sync()
+
+			got := readLog(t, filepath.Join(dir, "after.log"))
+			if !strings.Contains(got, "live line") || !strings.Contains(got, "live_test.go") {
+				t.Errorf("after.log = %q, want the entry with the caller in live_test.go", got)
+			}
+		})
+	}
+}
+
+func TestLiveCoreWithCached(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T)
+		wantWith int64
+	}{
+		{"unchanged", func(*testing.T) {}, 0},
+		{"level changed", func(t *testing.T) {
+			if err := SetLevel("debug"); err != nil {
+				t.Fatal(err)
+			}
+		}, 0},
+		{"reinit", func(t *testing.T) {
+			if err := InitLogSetting(&LogConfig{Dir: t.TempDir(), FileName: "after", Level: "info", DisableConsole: true}); err != nil {
+				t.Fatal(err)
+			}
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: "info"})
+			var with int64
+			core, recorded := observer.New(zapcore.DebugLevel)
+			defer AddCore(withCountingCore{core, &with})()
+
+			// 附加了字段的logger在默认logger不变时只生成一次core
+			// 重新初始化时其他日志也会调用With, 只统计lg写入期间的调用
+			lg := With("k", "v")
+			write := func() int64 {
+				n := atomic.LoadInt64(&with)
+				for i := 0; i < 3; i++ {
+					lg.Info("cached")
+				}
+				return atomic.LoadInt64(&with) - n
+			}
+			if got := write(); got != 1 {
+				t.Errorf("With calls = %d, want 1", got)
+			}
+			tt.change(t)
+			if got := write(); got != tt.wantWith {
+				t.Errorf("With calls after change = %d, want %d", got, tt.wantWith)
+			}
+			entries := recorded.FilterMessage("cached").All()
+			if len(entries) != 6 {
+				t.Fatalf("entries = %d, want 6", len(entries))
+			}
+			for _, e := range entries {
+				if e.ContextMap()["k"] != "v" {
+					t.Errorf("fields = %v, want k", e.ContextMap())
+				}
+			}
+		})
+	}
+}
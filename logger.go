@@ -0,0 +1,642 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Logger 独立的日志实例, 拥有自己的日志文件和输出, 包级函数使用默认的Logger
+type Logger struct {
+	l *zap.SugaredLogger
+	*outputs
+}
+
+// outputs Logger使用的日志文件、输出和后台任务
+type outputs struct {
+	level zap.AtomicLevel
+	// consoleLevel fileLevel 控制台和日志文件单独的级别, 未单独设置时为debug, 只按level过滤
+	consoleLevel zap.AtomicLevel
+	fileLevel    zap.AtomicLevel
+	// conf 实际生效的配置
+	conf           *LogConfig
+	logFileHook    *rollingFile
+	errLogFileHook *rollingFile
+	// mirrorFileHooks MirrorDirs中的日志文件
+	mirrorFileHooks []*rollingFile
+	// fileWriters 文件writer, 用于判断文件是否可写
+	fileWriters []*fileWriter
+	// bufferedWriters 缓冲writer, 停止时写入剩余的缓冲内容
+	bufferedWriters []*zapcore.BufferedWriteSyncer
+	// asyncSinks 异步输出, Drain时等待其写完
+	asyncSinks []asyncSink
+	// syslogRemote 远程syslog连接
+	syslogRemote *syslogWriter
+	// archiver 轮转文件归档
+	archiver *archiver
+	// fsyncer 定时fsync
+	fsyncer *fsyncer
+	// rotator 按时间轮转
+	rotator *rotator
+	// writerClosers Writers中实现了io.Closer的writer, Close时关闭
+	writerClosers []io.Closer
+	// zapOptions 调用位置、调用栈等zap选项, 不包含日志字段
+	zapOptions []zap.Option
+	// implicit 导入包时按默认配置创建, 日志文件延迟到第一次写入时创建
+	implicit bool
+}
+
+// NewLogger 按配置创建独立的Logger, 不影响包级函数使用的默认Logger, 日志级别与默认Logger互相独立
+// 按名称设置的日志级别、EntryTransformer等全局设置同样对其生效, 不再使用时调用Close关闭日志文件
+//
+//	audit, err := logs.NewLogger(&logs.LogConfig{FileName: "audit", Level: "info"})
+//	defer audit.Close()
+func NewLogger(conf *LogConfig) (*Logger, error) {
+	if err := validateConfig(conf); err != nil {
+		return nil, err
+	}
+	lg, err := newLogger(conf, zap.NewAtomicLevel(), nil)
+	if err != nil {
+		return nil, err
+	}
+	lg.start()
+	return lg, nil
+}
+
+// newLogger 按配置创建Logger, 日志级别使用atomicLevel, extra不为nil时日志同时写入extra, 后台任务需要调用start启动
+func newLogger(conf *LogConfig, atomicLevel zap.AtomicLevel, extra zapcore.Core) (*Logger, error) {
+	// 初始化的日志级别
+	logLevel, err := parseLevel(conf.Level)
+	if err != nil {
+		logLevel = zapcore.DebugLevel
+	}
+	// 控制台和日志文件的级别, 都未设置时只按logLevel过滤
+	consoleLevel := zap.NewAtomicLevelAt(traceLevel)
+	fileLevel := zap.NewAtomicLevelAt(traceLevel)
+	if conf.ConsoleLevel != "" || conf.FileLevel != "" {
+		consoleLevel.SetLevel(logLevel)
+		fileLevel.SetLevel(logLevel)
+		if lvl, err := parseLevel(conf.ConsoleLevel); conf.ConsoleLevel != "" && err == nil {
+			consoleLevel.SetLevel(lvl)
+		}
+		if lvl, err := parseLevel(conf.FileLevel); conf.FileLevel != "" && err == nil {
+			fileLevel.SetLevel(lvl)
+		}
+		logLevel = minLevel(consoleLevel.Level(), fileLevel.Level())
+	}
+	// 错误日志文件和stderr的级别
+	errLevel := zapcore.ErrorLevel
+	if lvl, err := parseLevel(conf.ErrFileLevel); conf.ErrFileLevel != "" && err == nil {
+		errLevel = lvl
+	}
+	ext := conf.FileExt
+	if ext == "" {
+		ext = ".log"
+	} else if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	var remote *syslogWriter
+	if conf.SyslogRemote != "" {
+		var err error
+		if remote, err = newSyslogWriter(conf.SyslogRemote); err != nil {
+			return nil, fmt.Errorf("logs: invalid SyslogRemote %q: %w", conf.SyslogRemote, err)
+		}
+	}
+	loc := time.UTC
+	if conf.LocalTime {
+		loc = time.Local
+	}
+	if conf.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(conf.Timezone); err != nil {
+			return nil, fmt.Errorf("logs: invalid Timezone %q: %w", conf.Timezone, err)
+		}
+	}
+	dir := conf.Dir
+	if dir == "" {
+		dir = defaultDir
+	}
+	if !conf.LazyFile && !conf.DisableFile {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("logs: create Dir %q: %w", dir, err)
+		}
+	}
+	maxSize := conf.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	newFileHook := func(filename string) *rollingFile {
+		return newRollingFile(&lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    maxSize,
+			MaxAge:     conf.MaxAge,
+			MaxBackups: conf.MaxBackups,
+			LocalTime:  conf.LocalTime,
+			Compress:   conf.Compress,
+		})
+	}
+	split := boolValue(conf.SplitErrorFile, true)
+	var mainHook, errHook *rollingFile
+	var mirrors, mirrorErrs []zapcore.WriteSyncer
+	var mirrorHooks []*rollingFile
+	// mirrorFiles 镜像目录的文件writer, 同样用于判断文件是否可写
+	var mirrorFiles []*fileWriter
+	if !conf.DisableFile {
+		// 保留20天, 分级别输出
+		mainHook = newFileHook(filepath.Join(dir, conf.FileName+ext))
+		errFileName := conf.ErrorFileName
+		if errFileName == "" {
+			errFileName = conf.FileName + "_err"
+		}
+		if split {
+			errHook = newFileHook(filepath.Join(dir, errFileName+ext))
+		}
+		for _, dir := range conf.MirrorDirs {
+			mirror := newFileHook(filepath.Join(dir, conf.FileName+ext))
+			mirrorFile := &fileWriter{Writer: mirror}
+			mirrors = append(mirrors, zapcore.AddSync(mirrorFile))
+			mirrorFiles = append(mirrorFiles, mirrorFile)
+			mirrorHooks = append(mirrorHooks, mirror)
+			if split {
+				mirrorErr := newFileHook(filepath.Join(dir, errFileName+ext))
+				mirrorErrFile := &fileWriter{Writer: mirrorErr}
+				mirrorErrs = append(mirrorErrs, zapcore.AddSync(mirrorErrFile))
+				mirrorFiles = append(mirrorFiles, mirrorErrFile)
+				mirrorHooks = append(mirrorHooks, mirrorErr)
+			}
+		}
+	}
+	timeLayout := conf.TimeLayout
+	if timeLayout == "" {
+		timeLayout = defaultTimeLayout
+	}
+	consoleColoredEncoderConfig := zap.NewProductionEncoderConfig()
+	consoleColoredEncoderConfig.TimeKey = "time"
+	color := enableColorFn()
+	consoleColoredEncoderConfig.EncodeLevel = themeLevelEncoder(conf.Theme)
+	if !color {
+		consoleColoredEncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+	if conf.Emoji {
+		consoleColoredEncoderConfig.EncodeLevel = emojiLevelEncoder(consoleColoredEncoderConfig.EncodeLevel)
+	}
+	consoleColoredEncoderConfig.EncodeTime = func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+		encoder.AppendString(t.In(loc).Format(timeLayout))
+	}
+	fileEncoderConfig := zap.NewProductionEncoderConfig()
+	fileEncoderConfig.TimeKey = "time"
+	fileEncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	// 为空时zap使用\n
+	fileEncoderConfig.LineEnding = conf.LineEnding
+	fileEncoderConfig.EncodeTime = func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+		encoder.AppendString(t.In(loc).Format(timeLayout))
+	}
+	if conf.EncoderConfigFn != nil {
+		conf.EncoderConfigFn(&consoleColoredEncoderConfig, &fileEncoderConfig)
+	}
+	// trace和自定义级别的名称在修改后的级别格式上输出
+	traceColor := ""
+	if color {
+		traceColor = traceLevelColor
+	}
+	consoleColoredEncoderConfig.EncodeLevel = customLevelEncoder(traceLevelEncoder(consoleColoredEncoderConfig.EncodeLevel, traceColor))
+	fileEncoderConfig.EncodeLevel = customLevelEncoder(traceLevelEncoder(fileEncoderConfig.EncodeLevel, ""))
+	// 日志级别由namedLevelCore按模块过滤, 各输出只按级别分流和按各自的级别过滤
+	allPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return true
+	})
+	filePriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return fileLevel.Enabled(lvl)
+	})
+	errFilePriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= errLevel && fileLevel.Enabled(lvl)
+	})
+	stdoutPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl < errLevel && consoleLevel.Enabled(lvl)
+	})
+	stderrPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= errLevel && consoleLevel.Enabled(lvl)
+	})
+	var consoleEncoder zapcore.Encoder = customLevelEncoding{zapcore.NewConsoleEncoder(consoleColoredEncoderConfig)}
+	var fileEncoder zapcore.Encoder
+	switch conf.Encoding {
+	case "json":
+		fileEncoder = zapcore.NewJSONEncoder(fileEncoderConfig)
+	case "bigquery":
+		fileEncoder = newBigQueryEncoder(conf.LineEnding)
+	default:
+		fileEncoder = zapcore.NewConsoleEncoder(fileEncoderConfig)
+	}
+	fileEncoder = customLevelEncoding{fileEncoder}
+	lvlName, errLvlName := strings.ToUpper(levelString(logLevel)), errLevel.CapitalString()
+	if logLevel > errLevel {
+		errLvlName = lvlName
+	}
+	var files []*fileWriter
+	var buffered []*zapcore.BufferedWriteSyncer
+	var async []asyncSink
+	var sinks []sink
+	if !conf.DisableFile {
+		files = []*fileWriter{{Writer: mainHook}}
+		// 镜像目录写入失败时其余目录照常写入, 错误输出到stderr
+		mainWriter := zapcore.NewMultiWriteSyncer(append([]zapcore.WriteSyncer{zapcore.AddSync(files[0])}, mirrors...)...)
+		if conf.MainBuffered {
+			w := &zapcore.BufferedWriteSyncer{WS: mainWriter}
+			buffered = append(buffered, w)
+			async = append(async, asyncSink{name: "main file", sync: w.Sync})
+			mainWriter = w
+		}
+		sinks = append(sinks, sink{"file", ">=" + lvlName, zapcore.NewCore(fileEncoder, mainWriter, filePriority)})
+		if errHook != nil {
+			files = append(files, &fileWriter{Writer: errHook})
+			errFileWriter := zapcore.NewMultiWriteSyncer(append([]zapcore.WriteSyncer{zapcore.AddSync(files[1])}, mirrorErrs...)...)
+			if conf.ErrorBuffered {
+				w := &zapcore.BufferedWriteSyncer{WS: errFileWriter}
+				buffered = append(buffered, w)
+				async = append(async, asyncSink{name: "error file", sync: w.Sync})
+				errFileWriter = w
+			}
+			sinks = append(sinks, sink{"error file", ">=" + errLvlName, zapcore.NewCore(fileEncoder, errFileWriter, errFilePriority)})
+		}
+		files = append(files, mirrorFiles...)
+	}
+	if !conf.DisableConsole {
+		sinks = append(sinks,
+			sink{"stdout", lvlName + "..<" + errLevel.CapitalString(), zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), stdoutPriority)},
+			sink{"stderr", ">=" + errLvlName, zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stderr), stderrPriority)},
+		)
+	}
+	var closers []io.Closer
+	for i, w := range conf.Writers {
+		sinks = append(sinks, sink{fmt.Sprintf("writer %d", i), ">=" + lvlName, zapcore.NewCore(fileEncoder, zapcore.Lock(zapcore.AddSync(w)), filePriority)})
+		if c, ok := w.(io.Closer); ok {
+			closers = append(closers, c)
+		}
+	}
+	if remote != nil {
+		sinks = append(sinks, sink{"syslog", ">=" + lvlName, &syslogCore{LevelEnabler: allPriority, w: remote, appName: conf.FileName}})
+	}
+	cores := teeSinks(sinks)
+	if conf.RouteDebug {
+		cores = newRouteDebugCore(sinks)
+	}
+	if extra != nil {
+		cores = zapcore.NewTee(cores, extra)
+	}
+	// 初始字段在MaxFields之前附加, 不计入字段数
+	cores = cores.With(initialFields(conf))
+	if conf.MaxFields > 0 {
+		cores = maxFieldsCore{Core: cores, max: conf.MaxFields}
+	}
+	// 默认error级别输出调用栈信息
+	stackLevel := zap.NewAtomicLevelAt(zap.ErrorLevel)
+	stackDisabled := conf.StacktraceLevel == "disabled"
+	if conf.StacktraceLevel != "" && !stackDisabled {
+		if lvl, err := parseLevel(conf.StacktraceLevel); err == nil {
+			stackLevel.SetLevel(lvl)
+		}
+	}
+	moduleLevels := make(map[string]zapcore.Level, len(conf.Levels))
+	for name, level := range conf.Levels {
+		lvl, _ := parseLevel(level)
+		moduleLevels[name] = lvl
+	}
+	var core zapcore.Core = namedLevelCore{transformCore{cores}, atomicLevel, newModuleLevels(moduleLevels)}
+	// o在创建完logger后赋值, 退出进程前写入其中缓冲的日志
+	var o *outputs
+	exit := exitHook{&o}
+	if conf.ErrorExits {
+		core = errorExitCore{core, exit}
+	}
+	// Desugar获取的logger同样使用的选项
+	opts := []zap.Option{zap.AddCallerSkip(conf.CallerSkip), zap.WithFatalHook(exit)}
+	if conf.Development {
+		opts = append(opts, zap.Development())
+	}
+	caller := boolValue(conf.EnableCaller, true)
+	if caller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if !stackDisabled {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+	if conf.PanicExits {
+		opts = append(opts, zap.WithPanicHook(exit))
+	}
+
+	// 包级函数和Logger的方法多一层调用, 跳过后输出调用方的文件和行号
+	logger := zap.New(core, append([]zap.Option{zap.AddCallerSkip(1)}, opts...)...)
+	atomicLevel.SetLevel(logLevel)
+
+	o = &outputs{
+		level:           atomicLevel,
+		consoleLevel:    consoleLevel,
+		fileLevel:       fileLevel,
+		conf:            copyConfig(conf),
+		logFileHook:     mainHook,
+		errLogFileHook:  errHook,
+		mirrorFileHooks: mirrorHooks,
+		fileWriters:     files,
+		bufferedWriters: buffered,
+		asyncSinks:      async,
+		syslogRemote:    remote,
+		zapOptions:      opts,
+		writerClosers:   closers,
+	}
+	o.conf.Level = levelString(logLevel)
+	o.conf.ExternalLogger = false
+	o.conf.ErrFileLevel = levelString(errLevel)
+	if conf.ConsoleLevel != "" || conf.FileLevel != "" {
+		o.conf.ConsoleLevel = levelString(consoleLevel.Level())
+		o.conf.FileLevel = levelString(fileLevel.Level())
+	}
+	o.conf.Dir = dir
+	o.conf.SplitErrorFile = BoolPtr(split)
+	o.conf.EnableCaller = BoolPtr(caller)
+	o.conf.TimeLayout = timeLayout
+	o.conf.MaxSize = maxSize
+	// syslog只在Drain时等待发送完成, 定时fsync和轮转不等待网络
+	if remote != nil {
+		o.asyncSinks = append(o.asyncSinks[:len(o.asyncSinks):len(o.asyncSinks)], asyncSink{name: "syslog", sync: remote.sync})
+	}
+	if conf.ArchiveDir != "" && !conf.DisableFile {
+		names := []string{filepath.Base(mainHook.Filename)}
+		if errHook != nil {
+			names = append(names, filepath.Base(errHook.Filename))
+		}
+		o.archiver = newArchiver(dir, conf.ArchiveDir, conf.MaxAge, conf.Compress, conf.LocalTime, names...)
+	}
+	if conf.PeriodicFsync > 0 && !conf.DisableFile {
+		var flush []func() error
+		for _, s := range async {
+			flush = append(flush, s.sync)
+		}
+		syncFiles := []string{mainHook.Filename}
+		if errHook != nil {
+			syncFiles = append(syncFiles, errHook.Filename)
+		}
+		for _, hook := range mirrorHooks {
+			syncFiles = append(syncFiles, hook.Filename)
+		}
+		o.fsyncer = newFsyncer(conf.PeriodicFsync, flush, syncFiles...)
+	}
+	if conf.RotateInterval != "" && !conf.DisableFile {
+		o.rotator = newRotator(conf.RotateInterval, loc, func() error {
+			// 先写入缓冲中的日志, 避免上一个周期的日志写入新文件
+			for _, s := range async {
+				_ = s.sync()
+			}
+			return o.rotate()
+		})
+	}
+	return &Logger{l: logger.Sugar(), outputs: o}, nil
+}
+
+// start 启动syslog发送、归档、定时fsync和按时间轮转
+func (o *outputs) start() {
+	if o.syslogRemote != nil {
+		o.syslogRemote.start()
+	}
+	if o.archiver != nil {
+		o.archiver.start()
+	}
+	if o.fsyncer != nil {
+		o.fsyncer.start()
+	}
+	if o.rotator != nil {
+		o.rotator.start()
+	}
+}
+
+// stop 停止后台任务和缓冲writer, 停止syslog发送并关闭日志文件
+// 包级函数派生的Logger写入时使用当前的默认Logger, 被替换的默认Logger停止后不再写入
+func (o *outputs) stop() error {
+	if o.rotator != nil {
+		o.rotator.close()
+	}
+	if o.fsyncer != nil {
+		o.fsyncer.close()
+	}
+	// 停止时会写入剩余的缓冲内容
+	for _, w := range o.bufferedWriters {
+		_ = w.Stop()
+	}
+	if o.syslogRemote != nil {
+		_ = o.syslogRemote.Close()
+	}
+	if o.archiver != nil {
+		o.archiver.close()
+	}
+	return o.eachFile((*rollingFile).Close)
+}
+
+// Close 写入缓冲中的日志, 停止后台任务并关闭日志文件
+// 由该Logger派生的Logger共用日志文件, 同样不能再使用
+func (lg *Logger) Close() error {
+	// 写入core中缓冲的日志, 如AddAsyncCore的发送队列
+	_ = lg.Sync()
+	o := lg.files()
+	err := o.stop()
+	for _, c := range o.writerClosers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Rotate 立即轮转lg的日志文件, 不写日志文件时不做任何事
+func (lg *Logger) Rotate() error {
+	return lg.files().rotate()
+}
+
+// files 获取lg的输出, 包级函数派生的Logger没有自己的输出, 使用当前默认Logger的输出
+func (lg *Logger) files() *outputs {
+	if lg.outputs == nil {
+		return std().outputs
+	}
+	return lg.outputs
+}
+
+// rotate 轮转日志文件, LazyFile时跳过还没有写入过的文件, 不提前创建
+func (o *outputs) rotate() error {
+	lazy := o.implicit || o.conf != nil && o.conf.LazyFile
+	return o.eachFile(func(hook *rollingFile) error {
+		if _, err := os.Stat(hook.Filename); lazy && os.IsNotExist(err) {
+			return nil
+		}
+		return hook.Rotate()
+	})
+}
+
+// setRetention 修改日志文件和归档的保存天数和保留个数, 不影响正在进行的写入
+func (o *outputs) setRetention(maxAge, maxBackups int) {
+	_ = o.eachFile(func(hook *rollingFile) error {
+		return hook.setRetention(maxAge, maxBackups)
+	})
+	if o.archiver != nil {
+		o.archiver.setMaxAge(maxAge)
+	}
+}
+
+// eachFile 对每个日志文件调用fn, 返回所有文件的错误
+func (o *outputs) eachFile(fn func(*rollingFile) error) error {
+	var msgs []string
+	hooks := append([]*rollingFile{o.logFileHook, o.errLogFileHook}, o.mirrorFileHooks...)
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if err := fn(hook); err != nil {
+			msgs = append(msgs, hook.Filename+": "+err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Named 获取指定名称的logger, 名称会输出在日志中, 与lg使用相同的输出
+// 多次调用Named时名称以.连接, 如scheduler.retry
+func (lg *Logger) Named(name string) *Logger {
+	return &Logger{l: lg.l.Named(name), outputs: lg.outputs}
+}
+
+// WithFields 获取附加了fields中字段的logger, 字段按键排序, fields为nil时返回lg
+func (lg *Logger) WithFields(fields map[string]interface{}) *Logger {
+	if fields == nil {
+		return lg
+	}
+	return lg.With(mapFields(fields)...)
+}
+
+// Sugar 获取lg对应的*zap.SugaredLogger, 用于需要zap类型的场景, 如NewContext
+func (lg *Logger) Sugar() *zap.SugaredLogger {
+	return lg.l.Desugar().WithOptions(zap.AddCallerSkip(-1)).Sugar()
+}
+
+// AddCallerSkip 获取调用位置额外跳过n层调用的logger, 用于在封装函数中输出日志
+//
+//	func logFailure(err error) { logs.Skip(1).Errorw("operation failed", "error", err) }
+func (lg *Logger) AddCallerSkip(n int) *Logger {
+	return &Logger{l: lg.l.Desugar().WithOptions(zap.AddCallerSkip(n)).Sugar(), outputs: lg.outputs}
+}
+
+// With 获取附加了日志字段的logger, 保留lg的名称
+func (lg *Logger) With(args ...interface{}) *Logger {
+	return &Logger{l: lg.l.With(args...), outputs: lg.outputs}
+}
+
+func (lg *Logger) Trace(v ...interface{}) {
+	lg.l.Log(traceLevel, v...)
+}
+
+func (lg *Logger) Tracef(format string, v ...interface{}) {
+	lg.l.Logf(traceLevel, format, v...)
+}
+
+func (lg *Logger) Tracew(format string, keysAndValues ...interface{}) {
+	lg.l.Logw(traceLevel, format, keysAndValues...)
+}
+
+func (lg *Logger) Debug(v ...interface{}) {
+	lg.l.Debug(v...)
+}
+
+func (lg *Logger) Debugf(format string, v ...interface{}) {
+	lg.l.Debugf(format, v...)
+}
+
+func (lg *Logger) Debugw(format string, keysAndValues ...interface{}) {
+	lg.l.Debugw(format, keysAndValues...)
+}
+
+func (lg *Logger) Info(v ...interface{}) {
+	lg.l.Info(v...)
+}
+
+func (lg *Logger) Infof(format string, v ...interface{}) {
+	lg.l.Infof(format, v...)
+}
+
+func (lg *Logger) Infow(format string, keysAndValues ...interface{}) {
+	lg.l.Infow(format, keysAndValues...)
+}
+
+func (lg *Logger) Warn(v ...interface{}) {
+	lg.l.Warn(v...)
+}
+
+func (lg *Logger) Warnf(format string, v ...interface{}) {
+	lg.l.Warnf(format, v...)
+}
+
+func (lg *Logger) Warnw(format string, keysAndValues ...interface{}) {
+	lg.l.Warnw(format, keysAndValues...)
+}
+
+func (lg *Logger) Error(v ...interface{}) {
+	lg.l.Error(v...)
+}
+
+func (lg *Logger) Errorf(format string, v ...interface{}) {
+	lg.l.Errorf(format, v...)
+}
+
+func (lg *Logger) Errorw(format string, keysAndValues ...interface{}) {
+	lg.l.Errorw(format, keysAndValues...)
+}
+
+func (lg *Logger) DPanic(v ...interface{}) {
+	lg.l.DPanic(v...)
+}
+
+func (lg *Logger) DPanicf(format string, v ...interface{}) {
+	lg.l.DPanicf(format, v...)
+}
+
+func (lg *Logger) DPanicw(format string, keysAndValues ...interface{}) {
+	lg.l.DPanicw(format, keysAndValues...)
+}
+
+func (lg *Logger) Fatal(v ...interface{}) {
+	lg.l.Fatal(v...)
+}
+
+func (lg *Logger) Fatalf(format string, v ...interface{}) {
+	lg.l.Fatalf(format, v...)
+}
+
+func (lg *Logger) Fatalw(format string, keysAndValues ...interface{}) {
+	lg.l.Fatalw(format, keysAndValues...)
+}
+
+func (lg *Logger) Panic(v ...interface{}) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	lg.l.Panic(v...)
+}
+
+func (lg *Logger) Panicf(format string, v ...interface{}) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	lg.l.Panicf(format, v...)
+}
+
+func (lg *Logger) Panicw(format string, keysAndValues ...interface{}) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	lg.l.Panicw(format, keysAndValues...)
+}
+
+func (lg *Logger) Sync() error {
+	return lg.l.Sync()
+}
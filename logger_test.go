@@ -0,0 +1,812 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLazyFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(lg *Logger)
+		want   bool
+	}{
+		{"no write", func(lg *Logger) {}, false},
+		{"sync", func(lg *Logger) { _ = lg.Sync() }, false},
+		{"rotate", func(lg *Logger) { _ = lg.Rotate() }, false},
+		{"write", func(lg *Logger) { lg.Info("first write") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "logs")
+			lg, err := NewLogger(&LogConfig{Dir: dir, FileName: "lazy", LazyFile: true, DisableConsole: true, RotateInterval: "daily"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer lg.Close()
+
+			tt.action(lg)
+			_, err = os.Stat(filepath.Join(dir, "lazy.log"))
+			if got := err == nil; got != tt.want {
+				t.Errorf("log file exists = %v, want %v", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "lazy_err.log")); !os.IsNotExist(err) {
+				t.Errorf("error log file created without an error entry, stat error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoggerClose(t *testing.T) {
+	dir := t.TempDir()
+	lg, err := NewLogger(&LogConfig{Dir: dir, FileName: "close", DisableConsole: true, MainBuffered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Info("buffered entry")
+	if err := lg.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLog(t, filepath.Join(dir, "close.log")); !strings.Contains(got, "buffered entry") {
+		t.Errorf("log file after Close = %q, want buffered entry", got)
+	}
+}
+
+func TestSplitErrorFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		split     *bool
+		wantSplit bool
+	}{
+		{"default", nil, true},
+		{"enabled", BoolPtr(true), true},
+		{"disabled", BoolPtr(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", SplitErrorFile: tt.split})
+			Info("info line")
+			Error("error line")
+			if err := Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			if err := Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+
+			var main string
+			entries, _ := os.ReadDir(dir)
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), "test-") && !strings.Contains(e.Name(), "_err") {
+					main += readLog(t, filepath.Join(dir, e.Name()))
+				}
+			}
+			if n := strings.Count(main, "error line"); n != 1 {
+				t.Errorf("error line in main file %d times, want 1", n)
+			}
+			_, err := os.Stat(filepath.Join(dir, "test_err.log"))
+			if hasErrFile := err == nil; hasErrFile != tt.wantSplit {
+				t.Errorf("error file exists = %v, want %v", hasErrFile, tt.wantSplit)
+			}
+			if got := *GetLogConf().SplitErrorFile; got != tt.wantSplit {
+				t.Errorf("GetLogConf().SplitErrorFile = %v, want %v", got, tt.wantSplit)
+			}
+		})
+	}
+}
+
+func TestEnableCaller(t *testing.T) {
+	tests := []struct {
+		name   string
+		enable *bool
+		write  func(msg string)
+		want   bool
+	}{
+		{"package function", nil, func(msg string) { Info(msg) }, true},
+		{"named logger", nil, func(msg string) { Named("db").Info(msg) }, true},
+		{"with fields", BoolPtr(true), func(msg string) { With("k", "v").Info(msg) }, true},
+		{"disabled", BoolPtr(false), func(msg string) { Info(msg) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json", EnableCaller: tt.enable})
+			tt.write("caller line")
+			_ = Sync()
+			var entry map[string]interface{}
+			for _, line := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(line, "caller line") {
+					if err := json.Unmarshal([]byte(line), &entry); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			caller, ok := entry["caller"].(string)
+			if ok != tt.want {
+				t.Fatalf("caller = %q, want present %v", caller, tt.want)
+			}
+			// 调用位置为测试中的调用, 而不是本包的封装函数
+			if tt.want && !regexp.MustCompile(`/logger_test\.go:\d+$`).MatchString(caller) {
+				t.Errorf("caller = %q, want logger_test.go:<line>", caller)
+			}
+		})
+	}
+}
+
+func TestFileExt(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		want string
+	}{
+		{"default", "", ".log"},
+		{"with dot", ".out", ".out"},
+		{"without dot", "txt", ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", FileExt: tt.ext})
+			Error("error line")
+			if err := Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			_ = Sync()
+
+			for _, name := range []string{"test" + tt.want, "test_err" + tt.want} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("stat %s: %v", name, err)
+				}
+			}
+			// 轮转后的文件名为 FileName-时间FileExt
+			rotated, _ := filepath.Glob(filepath.Join(dir, "test-*"+tt.want))
+			if len(rotated) == 0 {
+				t.Errorf("no rotated file with extension %s", tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		errName string
+		ext     string
+		want    string
+	}{
+		{"default", "", "", "test_err.log"},
+		{"custom", "errors", "", "errors.log"},
+		{"custom with ext", "errors", "txt", "errors.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", ErrorFileName: tt.errName, FileExt: tt.ext, MirrorDirs: []string{t.TempDir()}})
+			Info("info line")
+			Error("error line")
+			_ = Sync()
+
+			for _, d := range []string{dir, GetLogConf().MirrorDirs[0]} {
+				got := readLog(t, filepath.Join(d, tt.want))
+				if !strings.Contains(got, "error line") || strings.Contains(got, "info line") {
+					t.Errorf("%s = %q, want only the error line", filepath.Join(d, tt.want), got)
+				}
+			}
+			if tt.errName != "" {
+				if _, err := os.Stat(filepath.Join(dir, "test_err"+filepath.Ext(tt.want))); !os.IsNotExist(err) {
+					t.Errorf("default error file stat = %v, want it not created", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMirrorDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		split    *bool
+		buffered bool
+		wantErr  bool
+	}{
+		{"split", nil, false, true},
+		{"single file", BoolPtr(false), false, false},
+		{"buffered", nil, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirrors := []string{t.TempDir(), filepath.Join(t.TempDir(), "nested")}
+			dir := initTestLogger(t, &LogConfig{Level: "info", SplitErrorFile: tt.split, MainBuffered: tt.buffered, ErrorBuffered: tt.buffered, MirrorDirs: mirrors})
+			Info("info line")
+			Error("error line")
+			_ = Sync()
+
+			for _, d := range append([]string{dir}, mirrors...) {
+				main := readLog(t, filepath.Join(d, "test.log"))
+				if !strings.Contains(main, "info line") || !strings.Contains(main, "error line") {
+					t.Errorf("%s main file = %q, want both lines", d, main)
+				}
+				errFile := readLog(t, filepath.Join(d, "test_err.log"))
+				if got := strings.Contains(errFile, "error line") && !strings.Contains(errFile, "info line"); got != tt.wantErr {
+					t.Errorf("%s error file = %q, want only the error line: %v", d, errFile, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestMirrorDirsRotate(t *testing.T) {
+	mirror := t.TempDir()
+	dir := initTestLogger(t, &LogConfig{Level: "info", MirrorDirs: []string{mirror}})
+	Error("before rotate")
+	if err := Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	Error("after rotate")
+	_ = Sync()
+
+	for _, d := range []string{dir, mirror} {
+		for _, name := range []string{"test", "test_err"} {
+			rotated, _ := filepath.Glob(filepath.Join(d, name+"-*.log"))
+			if len(rotated) != 1 || !strings.Contains(readLog(t, rotated[0]), "before rotate") {
+				t.Errorf("%s rotated %s files = %v, want one with the line before rotate", d, name, rotated)
+			}
+			if got := readLog(t, filepath.Join(d, name+".log")); strings.Contains(got, "before rotate") || !strings.Contains(got, "after rotate") {
+				t.Errorf("%s %s.log = %q, want only the line after rotate", d, name, got)
+			}
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		wantJSON bool
+	}{
+		{"default", "", false},
+		{"console", "console", false},
+		{"json", "json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Level: "info", Encoding: tt.encoding}
+			stdout, _ := initConsoleLogger(t, c)
+			Infow("structured", "user", 42, "tags", []string{"a", "b"})
+			_ = Sync()
+
+			var line string
+			for _, l := range strings.Split(readLog(t, filepath.Join(c.Dir, "test.log")), "\n") {
+				if strings.Contains(l, "structured") {
+					line = l
+				}
+			}
+			var entry map[string]interface{}
+			isJSON := json.Unmarshal([]byte(line), &entry) == nil
+			if isJSON != tt.wantJSON {
+				t.Fatalf("file line %q, want JSON %v", line, tt.wantJSON)
+			}
+			if tt.wantJSON {
+				for _, key := range []string{"time", "level", "msg", "caller"} {
+					if _, ok := entry[key]; !ok {
+						t.Errorf("entry %v missing key %s", entry, key)
+					}
+				}
+				// Infow的字段为JSON字段, 而不是拼接在msg中
+				if entry["msg"] != "structured" || entry["user"] != float64(42) || len(entry["tags"].([]interface{})) != 2 {
+					t.Errorf("entry = %v, want user and tags as JSON fields", entry)
+				}
+			}
+			// 控制台输出不受Encoding影响
+			if got := stdout(); !strings.Contains(got, "structured") || strings.HasPrefix(strings.TrimSpace(got), "{") {
+				t.Errorf("stdout = %q, want console encoding", got)
+			}
+		})
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		name       string
+		encoding   string
+		lineEnding string
+		want       string
+	}{
+		{"default", "", "", "\n"},
+		{"console CRLF", "console", "\r\n", "\r\n"},
+		{"json CRLF", "json", "\r\n", "\r\n"},
+		{"bigquery CRLF", "bigquery", "\r\n", "\r\n"},
+		{"bigquery default", "bigquery", "", "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			c := &LogConfig{Level: "info", Encoding: tt.encoding, LineEnding: tt.lineEnding, Writers: []io.Writer{&w}, StacktraceLevel: "disabled"}
+			stdout, stderr := initConsoleLogger(t, c)
+			Info("first line")
+			Error("second line")
+			_ = Sync()
+
+			// 每行以换行符结尾, 文件和Writers相同
+			for name, got := range map[string]string{
+				"main file":  readLog(t, filepath.Join(c.Dir, "test.log")),
+				"error file": readLog(t, filepath.Join(c.Dir, "test_err.log")),
+				"writer":     w.String(),
+			} {
+				lines := strings.SplitAfter(strings.TrimSuffix(got, tt.want), tt.want)
+				if !strings.HasSuffix(got, tt.want) {
+					t.Errorf("%s = %q, want it to end with %q", name, got, tt.want)
+				}
+				for _, line := range lines {
+					if strings.Contains(strings.TrimSuffix(line, tt.want), "\n") {
+						t.Errorf("%s line = %q, want only %q line endings", name, line, tt.want)
+					}
+				}
+			}
+			// 控制台不受LineEnding影响
+			if out := stdout() + stderr(); strings.Contains(out, "\r") {
+				t.Errorf("console = %q, want \\n line endings", out)
+			}
+		})
+	}
+}
+
+func TestDisableFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      func()
+		wantStdout string
+		wantStderr string
+	}{
+		{"info to stdout", func() { Info("info line") }, "info line", ""},
+		{"error to stderr with stack", func() { Error("error line") }, "", "error line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Dir: filepath.Join(t.TempDir(), "logs"), Level: "info", DisableFile: true}
+			stdout, stderr := initConsoleLogger(t, c)
+			tt.write()
+			if err := Sync(); err != nil {
+				t.Errorf("Sync: %v", err)
+			}
+
+			if got := stdout(); !strings.Contains(got, tt.wantStdout) {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+			// 错误日志同时输出调用栈
+			if got := stderr(); tt.wantStderr != "" && (!strings.Contains(got, tt.wantStderr) || !strings.Contains(got, "TestDisableFile")) {
+				t.Errorf("stderr = %q, want %q with the stack trace", got, tt.wantStderr)
+			}
+			if _, err := os.Stat(c.Dir); !os.IsNotExist(err) {
+				t.Errorf("Dir created with DisableFile, stat error: %v", err)
+			}
+			if !GetLogConf().DisableFile {
+				t.Error("GetLogConf().DisableFile = false")
+			}
+		})
+	}
+}
+
+func TestDisableConsole(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func()
+		file  string
+		want  string
+	}{
+		{"info", func() { Info("info line") }, "test.log", "info line"},
+		{"error", func() { Error("error line") }, "test_err.log", "error line"},
+		{"panic stack", func() {
+			defer PrintPanicStack()
+			panic("boom")
+		}, "test_err.log", "frame 0:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Level: "info", DisableConsole: true}
+			stdout, stderr := initConsoleLogger(t, c)
+			tt.write()
+			_ = Sync()
+
+			if got := readLog(t, filepath.Join(c.Dir, tt.file)); !strings.Contains(got, tt.want) {
+				t.Errorf("%s = %q, want %q", tt.file, got, tt.want)
+			}
+			if out, errOut := stdout(), stderr(); out != "" || errOut != "" {
+				t.Errorf("console output with DisableConsole: stdout %q, stderr %q", out, errOut)
+			}
+
+			// 运行时重新初始化开启控制台输出
+			c.DisableConsole = false
+			if err := InitLogSetting(c); err != nil {
+				t.Fatal(err)
+			}
+			tt.write()
+			if out, errOut := stdout(), stderr(); !strings.Contains(out+errOut, tt.want) {
+				t.Errorf("console output after enabling = %q, want %q", out+errOut, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		want   string
+	}{
+		{"default", "", defaultTimeLayout},
+		{"RFC3339Nano", time.RFC3339Nano, time.RFC3339Nano},
+		{"seconds", "2006-01-02 15:04:05", "2006-01-02 15:04:05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Level: "info", Encoding: "json", TimeLayout: tt.layout}
+			stdout, _ := initConsoleLogger(t, c)
+			Info("timed")
+			_ = Sync()
+
+			var entry struct{ Time string }
+			for _, line := range strings.Split(readLog(t, filepath.Join(c.Dir, "test.log")), "\n") {
+				if strings.Contains(line, "timed") {
+					_ = json.Unmarshal([]byte(line), &entry)
+				}
+			}
+			var consoleTime string
+			for _, line := range strings.Split(stdout(), "\n") {
+				if strings.Contains(line, "timed") {
+					consoleTime = strings.Split(line, "\t")[0]
+				}
+			}
+			// 控制台和日志文件使用相同的格式, 按格式解析后再格式化与原内容相同
+			for name, got := range map[string]string{"file": entry.Time, "console": consoleTime} {
+				ts, err := time.Parse(tt.want, got)
+				if err != nil || ts.Format(tt.want) != got {
+					t.Errorf("%s time = %q, want layout %q (%v)", name, got, tt.want, err)
+				}
+			}
+		})
+	}
+	if err := InitLogSetting(&LogConfig{FileName: "x", TimeLayout: " "}); err == nil || !strings.Contains(err.Error(), "TimeLayout") {
+		t.Errorf("blank TimeLayout error = %v", err)
+	}
+}
+
+// fixedClock 总是返回同一时间的zapcore.Clock
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time                         { return time.Time(c) }
+func (c fixedClock) NewTicker(d time.Duration) *time.Ticker { return time.NewTicker(d) }
+
+func TestTimezone(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		localTime bool
+		timezone  string
+		want      string
+		wantErr   bool
+	}{
+		{"utc", false, "", "2024-01-01 00:00:00.000", false},
+		{"local", true, "", at.In(time.Local).Format(defaultTimeLayout), false},
+		{"named zone", false, "Asia/Shanghai", "2024-01-01 08:00:00.000", false},
+		{"zone overrides local", true, "America/New_York", "2023-12-31 19:00:00.000", false},
+		{"invalid zone", false, "Mars/Olympus", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			err := InitLogSetting(&LogConfig{Dir: dir, FileName: "test", Level: "info", DisableConsole: true, LocalTime: tt.localTime, Timezone: tt.timezone})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitLogSetting error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			L().WithOptions(zap.WithClock(fixedClock(at))).Info("fixed time")
+			_ = Sync()
+
+			var line string
+			for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(l, "fixed time") {
+					line = l
+				}
+			}
+			if !strings.HasPrefix(line, tt.want+"\t") {
+				t.Errorf("line = %q, want time %s", line, tt.want)
+			}
+			// 轮转文件名中的时间按LocalTime使用本地时间或UTC
+			if got := std().outputs.logFileHook.l.LocalTime; got != tt.localTime {
+				t.Errorf("lumberjack LocalTime = %v, want %v", got, tt.localTime)
+			}
+		})
+	}
+}
+
+func TestNewLoggerSeparateFiles(t *testing.T) {
+	defaultDir := initTestLogger(t, &LogConfig{})
+	dir := t.TempDir()
+	names := []string{"api", "worker"}
+	loggers := make([]*Logger, len(names))
+	for i, name := range names {
+		lg, err := NewLogger(&LogConfig{Dir: dir, FileName: name, DisableConsole: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		loggers[i] = lg
+	}
+
+	var wg sync.WaitGroup
+	for i, lg := range loggers {
+		wg.Add(1)
+		go func(name string, lg *Logger) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				lg.Infof("from %s", name)
+			}
+		}(names[i], lg)
+	}
+	wg.Wait()
+	for _, lg := range loggers {
+		if err := lg.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	}
+
+	for i, name := range names {
+		got := readLog(t, filepath.Join(dir, name+".log"))
+		if n := strings.Count(got, "from "+name); n != 100 {
+			t.Errorf("%s.log has %d own entries, want 100", name, n)
+		}
+		if other := names[1-i]; strings.Contains(got, "from "+other) {
+			t.Errorf("%s.log contains entries of %s", name, other)
+		}
+	}
+	_ = Sync()
+	if got := readLog(t, filepath.Join(defaultDir, "test.log")); strings.Contains(got, "from ") {
+		t.Errorf("default logger received entries of NewLogger: %q", got)
+	}
+}
+
+func TestErrFileLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		errLevel   string
+		wantErrLog []string
+		notErrLog  []string
+	}{
+		{"default", "", []string{"error line"}, []string{"warn line", "info line"}},
+		{"warn", "warn", []string{"warn line", "error line"}, []string{"info line"}},
+		{"dpanic", "dpanic", nil, []string{"warn line", "error line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Level: "info", ErrFileLevel: tt.errLevel}
+			stdout, stderr := initConsoleLogger(t, c)
+			Info("info line")
+			Warn("warn line")
+			Error("error line")
+			_ = Sync()
+
+			main := readLog(t, filepath.Join(c.Dir, "test.log"))
+			errLog := readLog(t, filepath.Join(c.Dir, "test_err.log"))
+			out, errOut := stdout(), stderr()
+			// stderr与错误文件使用相同的级别, 主文件总是包含所有日志
+			for _, want := range tt.wantErrLog {
+				if !strings.Contains(errLog, want) || !strings.Contains(errOut, want) || strings.Contains(out, want) {
+					t.Errorf("%q: error file %v, stderr %v, stdout %v; want error file and stderr only",
+						want, strings.Contains(errLog, want), strings.Contains(errOut, want), strings.Contains(out, want))
+				}
+			}
+			for _, notWant := range tt.notErrLog {
+				if strings.Contains(errLog, notWant) || strings.Contains(errOut, notWant) || !strings.Contains(out, notWant) {
+					t.Errorf("%q: error file %v, stderr %v, stdout %v; want stdout only",
+						notWant, strings.Contains(errLog, notWant), strings.Contains(errOut, notWant), strings.Contains(out, notWant))
+				}
+			}
+			for _, line := range []string{"info line", "warn line", "error line"} {
+				if !strings.Contains(main, line) {
+					t.Errorf("main file missing %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderConfigFn(t *testing.T) {
+	tests := []struct {
+		name        string
+		fn          func(console, file *zapcore.EncoderConfig)
+		wantFile    []string
+		wantConsole []string
+	}{
+		{"nil keeps defaults", nil, []string{`"msg":"encoded"`, `"level":"INFO"`, `"caller":"module/logger_test.go`}, []string{"\tencoded"}},
+		{"message key", func(console, file *zapcore.EncoderConfig) {
+			file.MessageKey = "message"
+		}, []string{`"message":"encoded"`}, []string{"\tencoded"}},
+		{"level and caller encoders", func(console, file *zapcore.EncoderConfig) {
+			file.EncodeLevel = zapcore.LowercaseLevelEncoder
+			file.EncodeCaller = zapcore.FullCallerEncoder
+			console.EncodeLevel = zapcore.LowercaseLevelEncoder
+		}, []string{`"level":"info"`, `"caller":"/`}, []string{"\tinfo\t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &LogConfig{Level: "info", Encoding: "json", EncoderConfigFn: tt.fn}
+			stdout, _ := initConsoleLogger(t, c)
+			Info("encoded")
+			_ = Sync()
+
+			var fileLine, consoleLine string
+			for _, line := range strings.Split(readLog(t, filepath.Join(c.Dir, "test.log")), "\n") {
+				if strings.Contains(line, "encoded") {
+					fileLine = line
+				}
+			}
+			for _, line := range strings.Split(stdout(), "\n") {
+				if strings.Contains(line, "encoded") {
+					consoleLine = line
+				}
+			}
+			for _, want := range tt.wantFile {
+				if !strings.Contains(fileLine, want) {
+					t.Errorf("file line = %s, want %s", fileLine, want)
+				}
+			}
+			for _, want := range tt.wantConsole {
+				if !strings.Contains(consoleLine, want) {
+					t.Errorf("console line = %q, want %q", consoleLine, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		wantWarn  bool
+		wantError bool
+	}{
+		{"default", "", false, true},
+		{"warn", "warn", true, true},
+		{"panic", "panic", false, false},
+		{"disabled", "disabled", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json", StacktraceLevel: tt.level})
+			Warn("warn line")
+			Error("error line")
+			_ = Sync()
+
+			stacks := map[string]bool{}
+			for _, line := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				var entry map[string]interface{}
+				if json.Unmarshal([]byte(line), &entry) != nil {
+					continue
+				}
+				stack, _ := entry["stacktrace"].(string)
+				stacks[fmt.Sprint(entry["msg"])] = strings.Contains(stack, "TestStacktraceLevel")
+			}
+			if stacks["warn line"] != tt.wantWarn || stacks["error line"] != tt.wantError {
+				t.Errorf("stacktrace on warn %v, error %v; want %v, %v", stacks["warn line"], stacks["error line"], tt.wantWarn, tt.wantError)
+			}
+		})
+	}
+}
+
+// closeCounter 记录Close次数的syncCounter
+type closeCounter struct {
+	syncCounter
+	closes int
+}
+
+func (w *closeCounter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closes++
+	return nil
+}
+
+func TestWriters(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		want     []string
+		notWant  []string
+	}{
+		{"console", "", []string{"\tINFO\t", "info line", "\tWARN\t"}, []string{"debug line"}},
+		{"json", "json", []string{`"level":"INFO","time"`, `"msg":"info line"`, `"msg":"warn line"`}, []string{"debug line"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &closeCounter{}
+			lg, err := NewLogger(&LogConfig{FileName: "w", Level: "info", Encoding: tt.encoding, DisableFile: true, DisableConsole: true, Writers: []io.Writer{&buf, w}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lg.Debug("debug line")
+			lg.Info("info line")
+			lg.Warn("warn line")
+			if err := lg.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			w.mu.Lock()
+			syncs := w.syncs
+			w.mu.Unlock()
+			for name, out := range map[string]string{"bytes.Buffer": buf.String(), "writer": w.buf.String()} {
+				for _, want := range tt.want {
+					if !strings.Contains(out, want) {
+						t.Errorf("%s = %q, want %q", name, out, want)
+					}
+				}
+				for _, notWant := range tt.notWant {
+					if strings.Contains(out, notWant) {
+						t.Errorf("%s = %q, want no %q", name, out, notWant)
+					}
+				}
+			}
+			if syncs == 0 {
+				t.Error("Sync did not sync the writer")
+			}
+			if err := lg.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if w.closes != 1 {
+				t.Errorf("Close closed the writer %d times, want 1", w.closes)
+			}
+		})
+	}
+}
+
+func TestBufferedFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		conf LogConfig
+		// 调用Sync之前已写入的文件
+		mainBefore, errBefore bool
+	}{
+		{"unbuffered", LogConfig{}, true, true},
+		{"error buffered", LogConfig{ErrorBuffered: true}, true, false},
+		{"main buffered", LogConfig{MainBuffered: true}, false, true},
+		{"both buffered", LogConfig{MainBuffered: true, ErrorBuffered: true}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			c.StacktraceLevel = "disabled"
+			dir := initTestLogger(t, &c)
+			Error("buffered error")
+			files := []struct {
+				path   string
+				before bool
+			}{
+				{filepath.Join(dir, "test.log"), tt.mainBefore},
+				{filepath.Join(dir, "test_err.log"), tt.errBefore},
+			}
+			for _, f := range files {
+				if got := strings.Contains(readLog(t, f.path), "buffered error"); got != f.before {
+					t.Errorf("%s written before Sync = %v, want %v", filepath.Base(f.path), got, f.before)
+				}
+			}
+			if err := Sync(); err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range files {
+				if !strings.Contains(readLog(t, f.path), "buffered error") {
+					t.Errorf("%s missing the entry after Sync", filepath.Base(f.path))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package logs
+
+import (
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogrSink 获取写入默认logger的logr.LogSink, 用于client-go、controller-runtime等使用logr的库
+// V(0)以info级别输出, V(1)到V(verbosity)以debug级别输出, 更高的V级别不输出
+// Error以error级别输出并写入错误日志文件, WithName对应Named, WithValues对应With, 重新初始化后依然有效
+//
+//	ctrl.SetLogger(logr.New(logs.NewLogrSink(2)))
+func NewLogrSink(verbosity int) logr.LogSink {
+	return &logrSink{l: Desugar().Sugar(), verbosity: verbosity}
+}
+
+type logrSink struct {
+	l         *zap.SugaredLogger
+	verbosity int
+}
+
+// logrLevel logr的V级别对应的日志级别
+func logrLevel(level int) zapcore.Level {
+	if level > 0 {
+		return zapcore.DebugLevel
+	}
+	return zapcore.InfoLevel
+}
+
+func (s *logrSink) Init(info logr.RuntimeInfo) {
+	// 跳过logr.Logger和logrSink的调用层级
+	s.l = s.l.WithOptions(zap.AddCallerSkip(info.CallDepth + 1))
+}
+
+func (s *logrSink) Enabled(level int) bool {
+	return level <= s.verbosity && s.l.Desugar().Core().Enabled(logrLevel(level))
+}
+
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if level > s.verbosity {
+		return
+	}
+	s.l.Logw(logrLevel(level), msg, keysAndValues...)
+}
+
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.l.Errorw(msg, append([]interface{}{zap.Error(err)}, keysAndValues...)...)
+}
+
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logrSink{l: s.l.With(keysAndValues...), verbosity: s.verbosity}
+}
+
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return &logrSink{l: s.l.Named(name), verbosity: s.verbosity}
+}
+
+func (s *logrSink) WithCallDepth(depth int) logr.LogSink {
+	return &logrSink{l: s.l.WithOptions(zap.AddCallerSkip(depth)), verbosity: s.verbosity}
+}
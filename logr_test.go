@@ -0,0 +1,135 @@
+package logs
+
+import (
+	"errors"
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLogrSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		log       func(l logr.Logger)
+		want      zapcore.Level
+		wantName  string
+		wantField []string
+		filtered  bool
+	}{
+		{"info", "debug", func(l logr.Logger) { l.Info("logr line", "k", "v") }, zapcore.InfoLevel, "", []string{"k"}, false},
+		{"V(1) debug", "debug", func(l logr.Logger) { l.V(1).Info("logr line") }, zapcore.DebugLevel, "", nil, false},
+		{"V(2) at verbosity", "debug", func(l logr.Logger) { l.V(2).Info("logr line") }, zapcore.DebugLevel, "", nil, false},
+		{"V(3) above verbosity", "debug", func(l logr.Logger) { l.V(3).Info("logr line") }, 0, "", nil, true},
+		{"V(1) at info level", "info", func(l logr.Logger) { l.V(1).Info("logr line") }, 0, "", nil, true},
+		{"error", "error", func(l logr.Logger) { l.Error(errors.New("boom"), "logr line", "k", 1) }, zapcore.ErrorLevel, "", []string{"error", "k"}, false},
+		{"error nil", "debug", func(l logr.Logger) { l.Error(nil, "logr line") }, zapcore.ErrorLevel, "", nil, false},
+		{"WithName", "debug", func(l logr.Logger) { l.WithName("ctrl").WithName("pod").Info("logr line") }, zapcore.InfoLevel, "ctrl.pod", nil, false},
+		{"WithValues", "debug", func(l logr.Logger) { l.WithValues("a", 1).V(1).Info("logr line", "b", 2) }, zapcore.DebugLevel, "", []string{"a", "b"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, tt.level)
+			tt.log(logr.New(NewLogrSink(2)))
+
+			entries := recorded.FilterMessage("logr line").All()
+			if tt.filtered {
+				if len(entries) != 0 {
+					t.Errorf("entries = %d, want 0", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.want || e.LoggerName != tt.wantName {
+				t.Errorf("entry = %s %q, want %s %q", e.Level, e.LoggerName, tt.want, tt.wantName)
+			}
+			if !strings.HasSuffix(e.Caller.File, "logr_test.go") {
+				t.Errorf("caller = %s, want logr_test.go", e.Caller.File)
+			}
+			fields := e.ContextMap()
+			for _, k := range tt.wantField {
+				if _, ok := fields[k]; !ok {
+					t.Errorf("fields = %v, want %s", fields, k)
+				}
+			}
+		})
+	}
+}
+
+func TestLogrSinkEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		level string
+		v     int
+		want  bool
+	}{
+		{"V(0) at info", "info", 0, true},
+		{"V(1) at info", "info", 1, false},
+		{"V(1) at debug", "debug", 1, true},
+		{"V(3) at debug", "debug", 3, false},
+		{"V(0) at warn", "warn", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: tt.level})
+			if got := logr.New(NewLogrSink(2)).V(tt.v).Enabled(); got != tt.want {
+				t.Errorf("V(%d).Enabled() = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogrSinkCallDepth(t *testing.T) {
+	recorded := observeDefault(t, "debug")
+	helper := func(l logr.Logger) {
+		l.WithCallDepth(1).Info("logr line")
+	}
+	helper(logr.New(NewLogrSink(0)))
+	_, _, line, _ := runtime.Caller(0)
+
+	entries := recorded.FilterMessage("logr line").All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if got := entries[0].Caller.Line; got != line-1 {
+		t.Errorf("caller line = %d, want %d", got, line-1)
+	}
+}
+
+func TestLogrSinkConcurrent(t *testing.T) {
+	recorded := observeDefault(t, "debug")
+	l := logr.New(NewLogrSink(1)).WithName("shared").WithValues("shared", true)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.WithValues("i", i).V(i % 2).Info("logr line")
+		}(i)
+	}
+	wg.Wait()
+	if got := recorded.FilterMessage("logr line").FilterField(Bool("shared", true)).Len(); got != n {
+		t.Errorf("entries = %d, want %d", got, n)
+	}
+}
+
+func TestLogrSinkFollowsReinit(t *testing.T) {
+	// 在初始化之前创建, 重新初始化后依然写入默认logger
+	l := logr.New(NewLogrSink(1)).WithName("early")
+	recorded := observeDefault(t, "info")
+	l.V(1).Info("logr line")
+	l.Info("logr line")
+
+	entries := recorded.FilterMessage("logr line").All()
+	if len(entries) != 1 || entries[0].Level != zapcore.InfoLevel || entries[0].LoggerName != "early" {
+		t.Errorf("entries = %v, want one info entry from early", entries)
+	}
+}
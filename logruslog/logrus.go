@@ -0,0 +1,78 @@
+// Package logruslog 将logrus的日志同时写入默认logger, 用于从logrus逐步迁移
+package logruslog
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sort"
+)
+
+// Hook 将logrus的日志同时写入默认logger的logrus.Hook
+// 日志级别一一对应, Data中的字段按键排序转为日志字段, panic和fatal级别只写入日志, 由logrus自身panic或退出
+type Hook struct {
+	// core 默认logger的core, 重新初始化后写入新的输出
+	core zapcore.Core
+}
+
+// NewHook 获取写入默认logger的logrus.Hook, 重新初始化后依然有效
+//
+//	logrus.AddHook(logruslog.NewHook())
+func NewHook() *Hook {
+	return &Hook{core: logs.Desugar().Core()}
+}
+
+// Levels 注册hook的logrus级别, 即所有级别
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 写入一条logrus日志, 日志时间和调用位置使用logrus记录的值
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	ent := zapcore.Entry{
+		Level:   logrusLevel(entry.Level),
+		Time:    entry.Time,
+		Message: entry.Message,
+	}
+	if entry.Caller != nil {
+		ent.Caller = zapcore.NewEntryCaller(entry.Caller.PC, entry.Caller.File, entry.Caller.Line, true)
+		ent.Caller.Function = entry.Caller.Function
+	}
+	// 直接写入core, 不经过zap.Logger, panic和fatal级别不会再次panic或退出
+	ce := h.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]zapcore.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Any(k, entry.Data[k]))
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+// logrusLevel logrus的日志级别对应的级别
+func logrusLevel(lvl logrus.Level) zapcore.Level {
+	switch lvl {
+	case logrus.PanicLevel:
+		return zapcore.PanicLevel
+	case logrus.FatalLevel:
+		return zapcore.FatalLevel
+	case logrus.ErrorLevel:
+		return zapcore.ErrorLevel
+	case logrus.WarnLevel:
+		return zapcore.WarnLevel
+	case logrus.InfoLevel:
+		return zapcore.InfoLevel
+	case logrus.DebugLevel:
+		return zapcore.DebugLevel
+	}
+	return logs.TraceLevel
+}
@@ -0,0 +1,87 @@
+package logruslog
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io"
+	"testing"
+)
+
+// observe 使用临时目录初始化默认logger, 返回写入默认logger的日志
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", Level: "trace", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+	core, recorded := observer.New(logs.TraceLevel)
+	t.Cleanup(logs.AddCore(core))
+	return recorded
+}
+
+func TestHook(t *testing.T) {
+	// 在初始化之前创建, 重新初始化后依然写入默认logger
+	hook := NewHook()
+	tests := []struct {
+		name  string
+		log   func(l *logrus.Logger)
+		want  zapcore.Level
+		panic bool
+	}{
+		{"trace", func(l *logrus.Logger) { l.Trace("msg") }, logs.TraceLevel, false},
+		{"debug", func(l *logrus.Logger) { l.Debug("msg") }, zapcore.DebugLevel, false},
+		{"info", func(l *logrus.Logger) { l.Info("msg") }, zapcore.InfoLevel, false},
+		{"warn", func(l *logrus.Logger) { l.Warn("msg") }, zapcore.WarnLevel, false},
+		{"error", func(l *logrus.Logger) { l.Error("msg") }, zapcore.ErrorLevel, false},
+		{"panic", func(l *logrus.Logger) { l.Panic("msg") }, zapcore.PanicLevel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observe(t)
+			l := logrus.New()
+			l.SetOutput(io.Discard)
+			l.SetLevel(logrus.TraceLevel)
+			l.AddHook(hook)
+
+			func() {
+				defer func() {
+					if x := recover(); (x != nil) != tt.panic {
+						t.Errorf("recovered %v, want panic %v", x, tt.panic)
+					}
+				}()
+				tt.log(l)
+			}()
+			entries := recorded.All()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			if e := entries[0]; e.Level != tt.want || e.Message != "msg" {
+				t.Errorf("entry = %s %q, want %s", e.Level, e.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookFields(t *testing.T) {
+	recorded := observe(t)
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetReportCaller(true)
+	l.AddHook(NewHook())
+	l.WithFields(logrus.Fields{"b": 2, "a": "x"}).Info("with fields")
+
+	entries := recorded.All()
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	// 默认logger的host pid字段在前
+	e := entries[0]
+	n := len(e.Context)
+	if n < 2 || e.Context[n-2].Key != "a" || e.Context[n-1].Key != "b" {
+		t.Errorf("fields = %v, want a and b sorted by key", e.Context)
+	}
+	if !e.Caller.Defined || e.Caller.Function == "" {
+		t.Errorf("caller = %+v, want the logrus caller", e.Caller)
+	}
+}
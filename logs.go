@@ -1,196 +1,486 @@
 package logs
 
 import (
+	"context"
 	"fmt"
-	"github.com/davecgh/go-spew/spew"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type LogConfig struct {
 	FileName  string // 日志文件名
-	Level     string // 日志级别 debug info warn error dpanic panic fatal
+	Level     string // 日志级别 trace debug info warn error dpanic panic fatal
 	MaxAge    int    // 保存时间 单位天
 	LocalTime bool   // true 使用本地时间  false 使用UTC时间
+	LazyFile  bool   // true 日志目录和文件延迟到第一次写文件时才创建
+
+	// 模块(Named的名称)的日志级别 如{"myapp": "debug", "thirdparty.client": "warn"}, 可以高于或低于Level
+	// 名称为a.b的logger依次使用a.b、a的设置, 都未设置时使用Level
+	Levels map[string]string
+
+	Service string // 服务名 不为空时每条日志附加service字段
+	Env     string // 运行环境 如prod staging, 不为空时每条日志附加env字段
+
+	// 每条日志附加的字段, 所有输出都包含, 自动附加host和pid字段, 可在这里设置同名字段覆盖
+	InitialFields map[string]interface{}
+
+	// 控制台和日志文件单独的日志级别, 为空时使用Level, 两者都为空时所有输出使用Level
+	// 设置后Level不再作为最低级别, 最低级别为两者中较低的一个, Levels中低于对应输出级别的设置不生效
+	ConsoleLevel string
+	FileLevel    string
+
+	DisableFile    bool // true 不写日志文件, 只输出到stdout和stderr, 文件相关的配置不生效
+	DisableConsole bool // true 不输出到stdout和stderr, 只写日志文件
+
+	Dir string // 日志目录 支持绝对路径, 默认./logs, 不存在时自动创建
+
+	MaxSize    int  // 单个日志文件的最大大小 单位MB, 0使用lumberjack默认的100MB
+	MaxBackups int  // 保留的轮转文件个数, 0为不限制
+	Compress   bool // true 轮转后的文件使用gzip压缩
+
+	ErrorFileName string // 错误日志文件名 为空时使用 FileName_err
+	ErrFileLevel  string // 写入错误日志文件和stderr的最低级别 默认error, 不能低于Level, 低于该级别的控制台日志输出到stdout
+	FileExt       string // 日志文件扩展名 默认.log, 轮转后的文件名为 FileName-时间FileExt
+
+	// 是否单独写错误日志文件, nil为true. false时所有级别只写入一个日志文件, ErrorFileName和ErrorBuffered不生效
+	//
+	//	conf.SplitErrorFile = logs.BoolPtr(false)
+	SplitErrorFile *bool
+
+	MainBuffered  bool // true 主日志文件使用缓冲异步写入
+	ErrorBuffered bool // true 错误日志文件使用缓冲异步写入
+
+	DumpContextValues bool // true 无法转为字符串的context值使用spew输出  false 跳过
+
+	SyslogRemote string // 远程syslog地址 如tcp://host:514 udp://host:514, 为空不启用
+	ArchiveDir   string // 轮转后的日志文件移动到该目录并在该目录按MaxAge清理, Compress时只移动压缩完成的文件, 为空不归档
+
+	PanicExits bool // true Panic输出日志后直接退出进程而不是panic
+	ErrorExits bool // true Error输出日志后退出进程, 用于测试/CI等严格模式
+
+	Theme string // 控制台颜色主题 dark light solarized, 为空使用默认颜色
+	Emoji bool   // true 控制台日志级别前显示emoji
+
+	MirrorDirs []string // 同时写入日志文件的镜像目录, 某个目录写入失败不影响其他目录
+
+	// 额外的输出 如socket或自定义轮转的writer, 使用日志文件的格式和级别, 不受DisableFile影响
+	// 实现了Sync的writer在Sync时同步, 实现了io.Closer的writer在Logger.Close时关闭
+	Writers []io.Writer
+
+	MaxFields int // 单条日志最多输出的字段数, 包括With附加的字段, 超出的字段被丢弃, 0为不限制
+
+	RouteDebug bool // true 启动后的前100条日志在stderr输出其写入和跳过的输出, 用于排查配置
+
+	StacktraceLevel string // 输出调用栈的最低级别 默认error, disabled不输出调用栈
+	EnableCaller    *bool  // 是否输出调用日志函数的文件和行号, nil为true
+	CallerSkip      int    // 调用位置额外跳过的调用层数, 对本包再做一层封装时设置为1, 对所有logger生效
+	Development     bool   // true 开发模式, DPanic输出日志后panic  false DPanic只输出日志
+
+	// 日志时间使用的时区 如Asia/Shanghai, 为空时按LocalTime使用本地时间或UTC
+	// lumberjack轮转文件名中的时间只支持本地时间和UTC, 仍按LocalTime处理
+	Timezone string
+
+	TimeLayout string // 控制台和日志文件的时间格式 默认2006-01-02 15:04:05.000, bigquery格式固定使用RFC3339
+	LineEnding string // 日志文件的换行符 默认\n, Windows下可设置为\r\n
+
+	// 日志文件的格式, 不影响控制台输出
+	// console: 默认, 以tab分隔的文本格式
+	// json: 每行一个json对象, 键为time level msg caller, 结构化字段作为json字段输出
+	// bigquery: 固定列的NDJSON, 列为timestamp severity message caller logger stacktrace fields,
+	// 结构化字段编码为json字符串放在fields列中
+	Encoding string
+
+	// 创建encoder前修改控制台和日志文件的EncoderConfig, 如修改键名、级别和调用位置的格式, 为nil不修改
+	// 调用时已设置默认值, bigquery格式的日志文件不使用EncoderConfig
+	//
+	//	conf.EncoderConfigFn = func(console, file *zapcore.EncoderConfig) { file.MessageKey = "message" }
+	EncoderConfigFn func(console, file *zapcore.EncoderConfig)
+
+	// 定时将日志文件fsync到磁盘的间隔, 0为不启用
+	// zap的Sync对日志文件不做fsync, 开启后断电时最多丢失一个间隔内的日志
+	PeriodicFsync time.Duration
+
+	// 按时间轮转日志文件 daily: 每天0点 hourly: 每小时整点, 时间按Timezone或LocalTime计算, 为空只按MaxSize轮转
+	// 与按大小轮转同时生效, 轮转后的文件名为 FileName-轮转时间FileExt, 同样按MaxAge和MaxBackups清理
+	RotateInterval string
+
+	// 只在GetLogConf的结果中有效 true表示通过SetLogger使用了外部的logger, 其他配置不再生效, InitLogSetting时忽略
+	ExternalLogger bool
 }
 
+const (
+	// defaultTimeLayout 默认的时间格式
+	defaultTimeLayout = "2006-01-02 15:04:05.000"
+	// defaultDir 默认的日志目录
+	defaultDir = "./logs"
+	// defaultMaxSize lumberjack默认的单个文件最大大小 单位MB
+	defaultMaxSize = 100
+)
+
 var (
-	zapDefault, _  = zap.NewProduction()
-	l              = zapDefault.Sugar()
-	logFileHook    *lumberjack.Logger
-	errLogFileHook *lumberjack.Logger
-	once           sync.Once
-
-	// default conf
-	conf = &LogConfig{
+	zapDefault, _ = zap.NewProduction()
+	// atomicLevel 默认Logger的日志级别, 重新初始化后仍然使用, 可通过SetLevel在运行时修改
+	atomicLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	// defaultLogger 包级函数使用的pkgLoggers, 通过l和lz获取
+	defaultLogger atomic.Value
+	// defaultLoggerGen 每次替换defaultLogger时递增, 使liveCore缓存的core失效
+	defaultLoggerGen uint64
+	// defaultStd 包级函数使用的默认*Logger, 通过std获取
+	defaultStd atomic.Value
+	once       sync.Once
+	// initMu 串行化默认Logger的替换, 保证被替换的Logger都被停止
+	initMu sync.Mutex
+
+	// panicMu 串行化Panic调用, 避免并发panic时日志交错
+	panicMu sync.Mutex
+
+	// logConf 实际生效的*LogConfig, 保存后不再修改, 通过getConf获取
+	logConf atomic.Value
+)
+
+// defaultLogConfig 默认的日志配置
+func defaultLogConfig() *LogConfig {
+	return &LogConfig{
 		FileName:  "log",
 		Level:     "debug",
 		MaxAge:    20,
 		LocalTime: true,
 	}
-)
+}
+
+// BoolPtr 获取指向b的指针, 用于设置SplitErrorFile等默认为true的配置
+func BoolPtr(b bool) *bool {
+	return &b
+}
+
+// boolValue p为nil时返回def
+func boolValue(p *bool, def bool) bool {
+	if p == nil {
+		return def
+	}
+	return *p
+}
 
 func init() {
+	setConf(defaultLogConfig())
+	setL(zapDefault.Sugar())
+	setStd(&Logger{l: l(), outputs: &outputs{
+		level:        atomicLevel,
+		consoleLevel: zap.NewAtomicLevelAt(traceLevel),
+		fileLevel:    zap.NewAtomicLevelAt(traceLevel),
+	}})
 	once.Do(func() {
-		InitLogSetting(conf)
+		// 导入包时不创建日志目录和文件, 第一次写文件或调用InitLogSetting时才创建
+		if err := initLogSetting(defaultLogConfig(), true); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
 	})
 }
 
-// GetLogConf 获取日志配置
+// pkgLoggers 包级函数使用的logger, 同时保存非sugar的logger, 避免每次调用Desugar
+type pkgLoggers struct {
+	sugar *zap.SugaredLogger
+	base  *zap.Logger
+	gen   uint64
+}
+
+// l 获取包级函数使用的logger, 重新初始化时整体替换, 并发使用时无需加锁
+func l() *zap.SugaredLogger {
+	return defaultLogger.Load().(pkgLoggers).sugar
+}
+
+// lz 获取与l对应的非sugar的logger, 调用层级与l相同
+func lz() *zap.Logger {
+	return defaultLogger.Load().(pkgLoggers).base
+}
+
+func setL(logger *zap.SugaredLogger) {
+	defaultLogger.Store(pkgLoggers{sugar: logger, base: logger.Desugar(), gen: atomic.AddUint64(&defaultLoggerGen, 1)})
+}
+
+// std 获取包级函数使用的默认Logger
+func std() *Logger {
+	return defaultStd.Load().(*Logger)
+}
+
+func setStd(lg *Logger) {
+	defaultStd.Store(lg)
+}
+
+// GetLogConf 获取日志配置的副本, 初始化后为实际生效的值, 修改副本不影响日志
 func GetLogConf() *LogConfig {
-	return conf
+	return copyConfig(getConf())
 }
 
-func InitLogSetting(conf *LogConfig) {
-	// 初始化的日志级别
-	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	_ = level.UnmarshalText([]byte(conf.Level))
+// getConf 获取实际生效的配置, 返回值只读, 并发使用时无需加锁
+func getConf() *LogConfig {
+	return logConf.Load().(*LogConfig)
+}
 
-	logLevel := level.Level()
-	// 保留20天, 分级别输出
-	logFileHook = &lumberjack.Logger{
-		Filename:  fmt.Sprintf("./logs/%s.log", conf.FileName),
-		MaxAge:    conf.MaxAge,
-		LocalTime: true,
+// setConf 保存实际生效的配置的副本, 之后c的修改不影响保存的配置
+func setConf(c *LogConfig) {
+	logConf.Store(copyConfig(c))
+}
+
+// MustInitLogSetting 与InitLogSetting相同, 配置无效时panic
+func MustInitLogSetting(conf *LogConfig) {
+	if err := InitLogSetting(conf); err != nil {
+		panic(err)
 	}
-	errLogFileHook = &lumberjack.Logger{
-		Filename:  fmt.Sprintf("./logs/%s_err.log", conf.FileName),
-		MaxAge:    conf.MaxAge,
-		LocalTime: true,
+}
+
+// InitLogSetting 按配置初始化日志, 配置无效或日志目录创建失败时返回错误, 此时之前的日志配置保持不变
+func InitLogSetting(conf *LogConfig) error {
+	return initLogSetting(conf, false)
+}
+
+// initLogSetting 按配置替换默认Logger, implicit为true时为导入包时的默认配置, 按LazyFile延迟创建文件
+func initLogSetting(conf *LogConfig, implicit bool) error {
+	if err := validateConfig(conf); err != nil {
+		return err
 	}
-	consoleColoredEncoderConfig := zap.NewProductionEncoderConfig()
-	consoleColoredEncoderConfig.TimeKey = "time"
-	consoleColoredEncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	consoleColoredEncoderConfig.EncodeTime = func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
-		encoder.AppendString(t.Format("2006-01-02 15:04:05.000"))
+	c := conf
+	if implicit {
+		c = copyConfig(conf)
+		c.LazyFile = true
 	}
-	fileEncoderConfig := zap.NewProductionEncoderConfig()
-	fileEncoderConfig.TimeKey = "time"
-	fileEncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
-	fileEncoderConfig.EncodeTime = func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
-		encoder.AppendString(t.Format("2006-01-02 15:04:05.000"))
+	lg, err := newLogger(c, atomicLevel, addedCoresCore{})
+	if err != nil {
+		return err
 	}
-	filePriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= logLevel
-	})
-	errPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= logLevel && lvl >= zapcore.ErrorLevel
-	})
-	stdoutPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= logLevel && lvl < zapcore.ErrorLevel
-	})
-	consoleEncoder := zapcore.NewConsoleEncoder(consoleColoredEncoderConfig)
-	fileEncoder := zapcore.NewConsoleEncoder(fileEncoderConfig)
-	cores := zapcore.NewTee(
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(logFileHook), filePriority),
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(errLogFileHook), errPriority),
-		zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stdout), stdoutPriority),
-		zapcore.NewCore(consoleEncoder, zapcore.Lock(os.Stderr), errPriority),
-	)
-	// error级别输出调用栈信息
-	logger := zap.New(cores, zap.AddStacktrace(zap.NewAtomicLevelAt(zap.ErrorLevel)))
-	l = logger.Sugar()
-}
-
-// PrintPanicStack 产生panic时的调用栈打印
+	if implicit {
+		lg.conf.LazyFile = conf.LazyFile
+		lg.implicit = true
+	}
+
+	initMu.Lock()
+	defer initMu.Unlock()
+	prev := std()
+	setStd(lg)
+	setL(lg.l)
+	_ = prev.stop()
+	lg.start()
+
+	// 重新初始化时输出发生变化的配置, 导入包时的默认配置不是用户设置的, 不输出
+	setConf(lg.conf)
+	if prev.conf != nil && !prev.implicit {
+		if diff := configDiff(prev.conf, lg.conf); len(diff) > 0 {
+			l().Infow("logging config changed", diff...)
+		}
+	}
+	return nil
+}
+
+// SetLogger 使用外部创建的logger作为包级函数的logger, 可在其他goroutine输出日志时调用
+// 之后的日志只输出到zl, SetLevel等配置不再生效, 之后调用InitLogSetting时重新使用按配置创建的logger
+//
+//	logs.SetLogger(platformLogger)
+func SetLogger(zl *zap.Logger) {
+	setL(zl.WithOptions(zap.AddCallerSkip(1)).Sugar())
+	c := copyConfig(getConf())
+	c.ExternalLogger = true
+	setConf(c)
+}
+
+// PrintPanicStack 产生panic时的调用栈打印, 需要输出context中的日志字段时使用RecoverWithContext
 func PrintPanicStack(extras ...interface{}) {
 	if x := recover(); x != nil {
-		Error(x)
+		printPanic(x, nil, extras...)
+	}
+}
+
+// RecoverWithContext 与PrintPanicStack相同恢复panic并输出调用栈, 同时输出ctx中的日志字段
+// 需要直接由defer调用
+//
+//	defer logs.RecoverWithContext(ctx)
+func RecoverWithContext(ctx context.Context, extras ...interface{}) {
+	if x := recover(); x != nil {
+		printPanic(x, ctx, extras...)
+	}
+}
+
+// printPanic 输出panic的值x、调用栈和extras, ctx不为nil时附加其中的日志字段
+func printPanic(x interface{}, ctx context.Context, extras ...interface{}) {
+	// 由defer调用, 调用位置为runtime, 不输出调用位置
+	logger := l().WithOptions(zap.WithCaller(false))
+	if fields := contextFields(ctx); len(fields) > 0 {
+		logger = logger.With(fields...)
+	}
+	logPanic(logger, x, true, extras...)
+}
+
+// LogPanic 以error级别输出恢复的panic值x, stack为true时逐帧输出调用栈, 用于中间件等恢复panic后输出
+func LogPanic(logger *zap.SugaredLogger, x interface{}, stack bool) {
+	logPanic(logger, x, stack)
+}
+
+// logPanic 以error级别输出panic的值x, stack为true时逐帧输出调用栈, 然后输出extras
+func logPanic(logger *zap.SugaredLogger, x interface{}, stack bool, extras ...interface{}) {
+	logger.Error(x)
+	if stack {
 		i := 0
 		funcName, file, line, ok := runtime.Caller(i)
 		for ok {
-			Errorf("frame %v:[func:%v,file:%v,line:%v]\n", i, runtime.FuncForPC(funcName).Name(), file, line)
+			logger.Errorf("frame %v:[func:%v,file:%v,line:%v]\n", i, runtime.FuncForPC(funcName).Name(), file, line)
 			i++
 			funcName, file, line, ok = runtime.Caller(i)
 		}
+	}
 
-		for k := range extras {
-			Errorf("EXTRAS#%v DATA:%v\n", k, spew.Sdump(extras[k]))
-		}
+	for k := range extras {
+		logger.Errorf("EXTRAS#%v DATA:%v\n", k, sdump(extras[k]))
 	}
 }
 
+// Trace 以低于debug的trace级别输出日志, 用于请求内容等大量的调试信息, 级别为debug及以上时不做任何事
+func Trace(v ...interface{}) {
+	l().Log(traceLevel, v...)
+}
+
+func Tracef(format string, v ...interface{}) {
+	l().Logf(traceLevel, format, v...)
+}
+
+func Tracew(format string, keysAndValues ...interface{}) {
+	l().Logw(traceLevel, format, keysAndValues...)
+}
+
 func Debug(v ...interface{}) {
-	l.Debug(v...)
+	l().Debug(v...)
 }
 
 func Debugf(format string, v ...interface{}) {
-	l.Debugf(format, v...)
+	l().Debugf(format, v...)
 }
 
 func Debugw(format string, keysAndValues ...interface{}) {
-	l.Debugw(format, keysAndValues...)
+	l().Debugw(format, keysAndValues...)
 }
 
 func Info(v ...interface{}) {
-	l.Info(v...)
+	l().Info(v...)
 }
 
 func Infof(format string, v ...interface{}) {
-	l.Infof(format, v...)
+	l().Infof(format, v...)
 }
 
 func Infow(format string, keysAndValues ...interface{}) {
-	l.Infow(format, keysAndValues...)
+	l().Infow(format, keysAndValues...)
 }
 
 func Warn(v ...interface{}) {
-	l.Warn(v...)
+	l().Warn(v...)
 }
 
 func Warnf(format string, v ...interface{}) {
-	l.Warnf(format, v...)
+	l().Warnf(format, v...)
 }
 
 func Warnw(format string, keysAndValues ...interface{}) {
-	l.Warnw(format, keysAndValues...)
+	l().Warnw(format, keysAndValues...)
 }
 
 func Error(v ...interface{}) {
-	l.Error(v...)
+	l().Error(v...)
 }
 
 func Errorf(format string, v ...interface{}) {
-	l.Errorf(format, v...)
+	l().Errorf(format, v...)
 }
 
 func Errorw(format string, keysAndValues ...interface{}) {
-	l.Errorw(format, keysAndValues...)
+	l().Errorw(format, keysAndValues...)
+}
+
+// DPanic 以dpanic级别输出日志, 开发模式下输出日志后panic
+func DPanic(v ...interface{}) {
+	l().DPanic(v...)
+}
+
+func DPanicf(format string, v ...interface{}) {
+	l().DPanicf(format, v...)
+}
+
+func DPanicw(format string, keysAndValues ...interface{}) {
+	l().DPanicw(format, keysAndValues...)
 }
 
 func Fatal(v ...interface{}) {
-	l.Fatal(v...)
+	l().Fatal(v...)
 }
 
 func Fatalf(format string, v ...interface{}) {
-	l.Fatalf(format, v...)
+	l().Fatalf(format, v...)
 }
 
 func Fatalw(format string, keysAndValues ...interface{}) {
-	l.Fatalw(format, keysAndValues...)
+	l().Fatalw(format, keysAndValues...)
 }
 
 func Panic(v ...interface{}) {
-	l.Panic(v...)
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	l().Panic(v...)
 }
 
 func Panicf(format string, v ...interface{}) {
-	l.Panicf(format, v...)
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	l().Panicf(format, v...)
 }
 
 func Panicw(format string, keysAndValues ...interface{}) {
-	l.Panicw(format, keysAndValues...)
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	l().Panicw(format, keysAndValues...)
+}
+
+// PanicWith 以panic级别输出msg和结构化字段, 字段写入日志后再以msg panic
+// 恢复时可使用PrintPanicStack输出调用栈, 开启PanicExits时输出日志后直接退出进程
+//
+//	logs.PanicWith("invalid state", "order", id, "state", st)
+func PanicWith(msg string, kv ...interface{}) {
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	l().Panicw(msg, kv...)
+}
+
+// Rotate 立即轮转日志文件和错误日志文件, 用于收集日志前或外部工具移动文件后, 不写日志文件时不做任何事
+func Rotate() error {
+	return std().Rotate()
 }
 
 func Sync() error {
-	return l.Sync()
+	return l().Sync()
+}
+
+// With 获取附加了日志字段的logger, 与默认logger使用相同的输出, 重新初始化后使用新的输出
+//
+//	logs.With("request_id", id).Infow("order created", "order", orderID)
+func With(args ...interface{}) *Logger {
+	return live().With(args...)
+}
+
+// WithFields 获取附加了fields中字段的logger, 字段按键排序
+//
+//	logs.WithFields(map[string]interface{}{"user": uid, "order": id}).Info("order created")
+func WithFields(fields map[string]interface{}) *Logger {
+	return With().WithFields(fields)
+}
+
+// Skip 获取调用位置额外跳过n层调用的logger, 与默认logger使用相同的输出, 用于在封装函数中输出日志
+func Skip(n int) *Logger {
+	return live().AddCallerSkip(n)
 }
 
-func With(args ...interface{}) *zap.SugaredLogger {
-	return l.With(args)
+// userLogger 获取交给调用方直接使用的logger, 去掉包级函数的调用层级
+func userLogger() *zap.SugaredLogger {
+	return Sugar()
 }
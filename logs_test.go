@@ -0,0 +1,621 @@
+package logs
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// initTestLogger 使用临时目录初始化默认logger, 测试结束后恢复为默认配置
+func initTestLogger(t testing.TB, c *LogConfig) string {
+	t.Helper()
+	if c.Dir == "" {
+		c.Dir = t.TempDir()
+	}
+	if c.FileName == "" {
+		c.FileName = "test"
+	}
+	c.DisableConsole = true
+	if err := InitLogSetting(c); err != nil {
+		t.Fatalf("InitLogSetting: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Sync()
+		if err := InitLogSetting(&LogConfig{FileName: "log", Level: "debug", LazyFile: true, DisableConsole: true, Dir: t.TempDir()}); err != nil {
+			t.Errorf("reset InitLogSetting: %v", err)
+		}
+	})
+	return c.Dir
+}
+
+// initConsoleLogger 与initTestLogger相同, 但保留控制台输出, 返回写入stdout和stderr的内容
+func initConsoleLogger(t *testing.T, c *LogConfig) (stdout, stderr func() string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := make([]*os.File, 2)
+	for i, name := range []string{"stdout", "stderr"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[i] = f
+	}
+	// 控制台输出在创建logger时绑定os.Stdout和os.Stderr, 重置logger之后再恢复
+	origOut, origErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = files[0], files[1]
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = origOut, origErr
+		files[0].Close()
+		files[1].Close()
+	})
+	if c.Dir == "" {
+		c.Dir = t.TempDir()
+	}
+	if c.FileName == "" {
+		c.FileName = "test"
+	}
+	if err := InitLogSetting(c); err != nil {
+		t.Fatalf("InitLogSetting: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Sync()
+		if err := InitLogSetting(&LogConfig{FileName: "log", Level: "debug", LazyFile: true, DisableConsole: true, Dir: t.TempDir()}); err != nil {
+			t.Errorf("reset InitLogSetting: %v", err)
+		}
+	})
+	read := func(f *os.File) func() string {
+		return func() string {
+			_ = Sync()
+			return readLog(t, f.Name())
+		}
+	}
+	return read(files[0]), read(files[1])
+}
+
+// observeDefault 将默认logger的日志同时写入返回的observer
+func observeDefault(t *testing.T, level string) *observer.ObservedLogs {
+	t.Helper()
+	initTestLogger(t, &LogConfig{Level: level})
+	core, recorded := observer.New(TraceLevel)
+	t.Cleanup(AddCore(core))
+	return recorded
+}
+
+// readLog 读取日志文件的内容, 文件不存在时返回空字符串
+func readLog(t testing.TB, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// runChild 在dir中以子进程运行当前测试, 子进程中childEnv环境变量为1
+// -test.run按/分别匹配每一级测试名, 每一级都需要完整匹配, 否则会同时运行名称包含当前子测试名的子测试
+func runChild(t *testing.T, dir string) ([]byte, error) {
+	t.Helper()
+	names := strings.Split(t.Name(), "/")
+	for i, name := range names {
+		names[i] = "^" + regexp.QuoteMeta(name) + "$"
+	}
+	cmd := exec.Command(os.Args[0], "-test.run="+strings.Join(names, "/"))
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), childEnv+"=1")
+	return cmd.CombinedOutput()
+}
+
+const childEnv = "LOGS_TEST_CHILD"
+
+func TestImportCreatesNoFiles(t *testing.T) {
+	if os.Getenv(childEnv) == "1" {
+		return
+	}
+	dir := t.TempDir()
+	if out, err := runChild(t, dir); err != nil {
+		t.Fatalf("child: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs")); !os.IsNotExist(err) {
+		t.Errorf("logs directory created before InitLogSetting, stat error: %v", err)
+	}
+}
+
+func TestInitLogSettingCreatesDir(t *testing.T) {
+	tests := []struct {
+		name string
+		conf LogConfig
+		want bool
+	}{
+		// 重新初始化时输出的info日志会写入文件, lazy使用warn级别
+		{"default", LogConfig{Level: "warn"}, true},
+		{"lazy", LogConfig{Level: "warn", LazyFile: true}, false},
+		{"disabled", LogConfig{DisableFile: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			c.Dir = filepath.Join(t.TempDir(), "nested", "logs")
+			initTestLogger(t, &c)
+			_, err := os.Stat(c.Dir)
+			if got := err == nil; got != tt.want {
+				t.Errorf("Dir exists = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetLogConfEffectiveValues(t *testing.T) {
+	tests := []struct {
+		name string
+		conf LogConfig
+		want LogConfig
+	}{
+		{
+			name: "explicit",
+			conf: LogConfig{MaxSize: 5, MaxBackups: 3, Compress: true},
+			want: LogConfig{MaxSize: 5, MaxBackups: 3, Compress: true},
+		},
+		{
+			name: "zero values keep lumberjack defaults",
+			conf: LogConfig{},
+			want: LogConfig{MaxSize: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			initTestLogger(t, &c)
+			got := GetLogConf()
+			if got.MaxSize != tt.want.MaxSize || got.MaxBackups != tt.want.MaxBackups || got.Compress != tt.want.Compress {
+				t.Errorf("GetLogConf() = MaxSize %d MaxBackups %d Compress %v, want %d %d %v",
+					got.MaxSize, got.MaxBackups, got.Compress, tt.want.MaxSize, tt.want.MaxBackups, tt.want.Compress)
+			}
+		})
+	}
+}
+
+func TestGetLogConfReturnsCopy(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info"})
+	c := GetLogConf()
+	c.Level = "error"
+	c.FileName = "changed"
+	if got := GetLogConf(); got.Level != "info" || got.FileName != "test" {
+		t.Errorf("GetLogConf() after modifying copy = %q %q, want info test", got.Level, got.FileName)
+	}
+}
+
+func TestRotationHonorsMaxBackups(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{MaxSize: 1, MaxBackups: 1})
+	line := strings.Repeat("x", 1024)
+	for i := 0; i < 3*1024; i++ {
+		Info(line)
+	}
+	_ = Sync()
+
+	backups := func() []string {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), "test-") {
+				names = append(names, e.Name())
+			}
+		}
+		return names
+	}
+	// lumberjack在后台goroutine删除多余的轮转文件, 等待清理完成
+	got := backups()
+	for deadline := time.Now().Add(5 * time.Second); len(got) != 1 && time.Now().Before(deadline); got = backups() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(got) != 1 {
+		t.Errorf("rotated files = %v, want MaxBackups 1", got)
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "test.log")); err != nil || fi.Size() > 1024*1024 {
+		t.Errorf("current log file = %v %v, want at most 1MB", fi, err)
+	}
+}
+
+func TestExtendRetention(t *testing.T) {
+	tests := []struct {
+		name                 string
+		maxAge, maxBackups   int
+		d                    time.Duration
+		wantAge, wantBackups int
+	}{
+		{"rounds days up", 2, 3, 36 * time.Hour, 4, 0},
+		{"backups only", 0, 3, time.Hour, 0, 0},
+		{"no cleanup", 0, 0, time.Hour, 0, 0},
+		{"non-positive duration", 2, 3, 0, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{MaxAge: tt.maxAge, MaxBackups: tt.maxBackups})
+			lg := std()
+			hook := lg.outputs.logFileHook
+			ExtendRetention(tt.d)
+			if std() != lg {
+				t.Fatal("ExtendRetention re-initialized the logger")
+			}
+			if age, backups := hook.retention(); age != tt.wantAge || backups != tt.wantBackups {
+				t.Errorf("extended = %d/%d, want %d/%d", age, backups, tt.wantAge, tt.wantBackups)
+			}
+			if got := GetLogConf(); got.MaxAge != tt.maxAge || got.MaxBackups != tt.maxBackups {
+				t.Errorf("GetLogConf = %d/%d, want the configured values", got.MaxAge, got.MaxBackups)
+			}
+			ExtendRetention(0)
+			if age, backups := hook.retention(); age != tt.maxAge || backups != tt.maxBackups {
+				t.Errorf("restored = %d/%d, want %d/%d", age, backups, tt.maxAge, tt.maxBackups)
+			}
+		})
+	}
+}
+
+func TestExtendRetentionExpires(t *testing.T) {
+	initTestLogger(t, &LogConfig{MaxAge: 2, MaxBackups: 3, ArchiveDir: t.TempDir()})
+	o := std().outputs
+	// 修改配置时并发写入和轮转
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				Info("during extension")
+				_ = Rotate()
+			}
+		}
+	}()
+	ExtendRetention(20 * time.Millisecond)
+	if got := atomic.LoadInt64(&o.archiver.maxAge); got != 3 {
+		t.Errorf("archive max age = %d, want 3", got)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	age, _ := o.logFileHook.retention()
+	_, backups := o.errLogFileHook.retention()
+	if age != 2 || backups != 3 || atomic.LoadInt64(&o.archiver.maxAge) != 2 {
+		t.Errorf("after expiry = %d/%d, want 2/3", age, backups)
+	}
+}
+
+func TestConfigConcurrentAccess(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_ = InitLogSetting(&LogConfig{Dir: dir, FileName: "test", DisableConsole: true})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = GetLogConf().FileName
+				_ = Snapshot()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				SetLogger(lz())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// openFiles 当前进程打开的文件数, 不支持时跳过测试
+func openFiles(t *testing.T) int {
+	t.Helper()
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skipf("count open files: %v", err)
+	}
+	return len(entries)
+}
+
+func TestReinitClosesPreviousFiles(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{})
+	Named("worker").Error("open both files")
+	before := openFiles(t)
+	for i := 0; i < 50; i++ {
+		if err := InitLogSetting(&LogConfig{Dir: dir, FileName: "test", DisableConsole: true}); err != nil {
+			t.Fatal(err)
+		}
+		Named("worker").Error("reopen both files")
+	}
+	if after := openFiles(t); after > before+2 {
+		t.Errorf("open files = %d after 50 re-inits, was %d", after, before)
+	}
+}
+
+func TestPackageWrappersCaller(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		write func(msg string)
+	}{
+		{"Info", func(msg string) { Info(msg) }},
+		{"Infof", func(msg string) { Infof("%s", msg) }},
+		{"Infow", func(msg string) { Infow(msg, "k", "v") }},
+		{"Error", func(msg string) { Error(msg) }},
+		{"InfoF", func(msg string) { InfoF(msg) }},
+		{"InfoCtx", func(msg string) { InfoCtx(ctx, msg) }},
+		{"Print", func(msg string) { Print(msg) }},
+		{"With", func(msg string) { With("k", "v").Info(msg) }},
+		{"Named", func(msg string) { Named("db").Info(msg) }},
+		{"WithContext", func(msg string) { WithContext(ctx).Info(msg) }},
+		{"L", func(msg string) { L().Info(msg) }},
+		{"Skip(0)", func(msg string) { Skip(0).Info(msg) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+			tt.write("caller line")
+			_ = Sync()
+
+			var entry struct{ Caller string }
+			for _, line := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(line, "caller line") {
+					if err := json.Unmarshal([]byte(line), &entry); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			// 调用位置为测试中的调用, 而不是本包的封装函数
+			if !strings.Contains(entry.Caller, "/logs_test.go:") {
+				t.Errorf("caller = %q, want logs_test.go", entry.Caller)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      LogConfig
+		wantFiles int
+	}{
+		{"main and error files", LogConfig{Level: "info"}, 4},
+		{"without error file", LogConfig{Level: "info", SplitErrorFile: BoolPtr(false)}, 2},
+		{"file disabled", LogConfig{Level: "info", DisableFile: true}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.conf
+			dir := initTestLogger(t, &c)
+			Info("before rotate")
+			Error("error before rotate")
+			if err := Rotate(); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			Info("after rotate")
+			Error("error after rotate")
+			_ = Sync()
+
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != tt.wantFiles {
+				t.Fatalf("files = %d, want %d", len(entries), tt.wantFiles)
+			}
+			if tt.wantFiles == 0 {
+				return
+			}
+			if got := readLog(t, filepath.Join(dir, "test.log")); strings.Contains(got, "before rotate") || !strings.Contains(got, "after rotate") {
+				t.Errorf("test.log = %q, want only entries after Rotate", got)
+			}
+			rotated, _ := filepath.Glob(filepath.Join(dir, "test-*.log"))
+			if len(rotated) != 1 || !strings.Contains(readLog(t, rotated[0]), "before rotate") {
+				t.Errorf("rotated files = %v, want one with the entries before Rotate", rotated)
+			}
+		})
+	}
+}
+
+// wrappedInfof 对本包再做一层封装, 用于测试CallerSkip
+func wrappedInfof(format string, args ...interface{}) {
+	Infof(format, args...)
+}
+
+// wrappedSkip 对Skip返回的logger再做一层封装
+func wrappedSkip(lg func() *Logger, msg string) {
+	lg().Info(msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerSkip int
+		write      func(msg string)
+	}{
+		{"CallerSkip", 1, func(msg string) { wrappedInfof("%s", msg) }},
+		{"Skip", 0, func(msg string) { wrappedSkip(func() *Logger { return Skip(1) }, msg) }},
+		{"Skip.With", 0, func(msg string) { wrappedSkip(func() *Logger { return Skip(1).With("k", "v") }, msg) }},
+		{"Named.AddCallerSkip", 0, func(msg string) { wrappedSkip(func() *Logger { return Named("db").AddCallerSkip(1) }, msg) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json", CallerSkip: tt.callerSkip,
+				EncoderConfigFn: func(console, file *zapcore.EncoderConfig) { file.FunctionKey = "func" }})
+			tt.write("caller line")
+			_ = Sync()
+
+			var entry struct{ Func string }
+			for _, line := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(line, "caller line") {
+					if err := json.Unmarshal([]byte(line), &entry); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			// 调用位置为表中调用封装函数的测试函数, 而不是封装函数
+			if !strings.Contains(entry.Func, "TestCallerSkip.func") {
+				t.Errorf("caller function = %q, want the table entry", entry.Func)
+			}
+		})
+	}
+}
+
+func TestDPanic(t *testing.T) {
+	tests := []struct {
+		name        string
+		development bool
+		write       func()
+		wantMsg     string
+	}{
+		{"production DPanic", false, func() { DPanic("dpanic line") }, "dpanic line"},
+		{"production DPanicf", false, func() { DPanicf("dpanic %s", "line") }, "dpanic line"},
+		{"production DPanicw", false, func() { DPanicw("dpanic line", "k", "v") }, "dpanic line"},
+		{"development DPanic", true, func() { DPanic("dpanic line") }, "dpanic line"},
+		{"development DPanicw", true, func() { DPanicw("dpanic line", "k", "v") }, "dpanic line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Development: tt.development})
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				tt.write()
+			}()
+			// 开发模式下输出日志后panic, 生产模式只输出日志
+			if (recovered != nil) != tt.development {
+				t.Errorf("recovered %v, want panic %v", recovered, tt.development)
+			}
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test_err.log"))
+			if !strings.Contains(got, "DPANIC") || !strings.Contains(got, tt.wantMsg) {
+				t.Errorf("error file = %q, want the DPANIC entry", got)
+			}
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		lg    func() *Logger
+		want  []string
+		wantN int
+	}{
+		{"pairs", func() *Logger { return With("user", 1, "path", "/a") }, []string{`"user":1`, `"path":"/a"`}, 2},
+		{"typed field", func() *Logger { return With(String("k", "v")) }, []string{`"k":"v"`}, 1},
+		{"chained", func() *Logger { return With("a", 1).With("b", 2) }, []string{`"a":1`, `"b":2`}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+			tt.lg().Info("with line")
+			_ = Sync()
+
+			var line string
+			for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(l, "with line") {
+					line = l
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("line = %s, want %s", line, want)
+				}
+			}
+			// 每个键值对是单独的字段, 不会合并为一个切片
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(entry) - len([]string{"level", "time", "caller", "msg", "host", "pid"}); n != tt.wantN {
+				t.Errorf("fields = %d, want %d in %s", n, tt.wantN, line)
+			}
+		})
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	tests := []struct {
+		name   string
+		write  func()
+		want   string
+		caller bool
+	}{
+		{"Info", func() { Info("external line") }, "external line", true},
+		{"Errorw", func() { Errorw("external line", "k", "v") }, "external line", true},
+		{"InfoF", func() { InfoF("external line") }, "external line", true},
+		// 由defer调用, 不输出调用位置
+		{"PrintPanicStack", func() {
+			defer PrintPanicStack()
+			panic("external line")
+		}, "external line", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			core, recorded := observer.New(zapcore.DebugLevel)
+			SetLogger(zap.New(core, zap.AddCaller()))
+			if !GetLogConf().ExternalLogger {
+				t.Error("GetLogConf().ExternalLogger = false after SetLogger")
+			}
+			tt.write()
+
+			entries := recorded.FilterMessage(tt.want).All()
+			if len(entries) != 1 {
+				t.Fatalf("entries in the external logger = %d, want 1", len(entries))
+			}
+			if c := entries[0].Caller; c.Defined != tt.caller || c.Defined && !strings.HasSuffix(c.File, "logs_test.go") {
+				t.Errorf("caller = %+v, want logs_test.go: %v", c, tt.caller)
+			}
+			_ = Sync()
+			if got := readLog(t, filepath.Join(dir, "test.log")); strings.Contains(got, tt.want) {
+				t.Errorf("entry written to the configured file after SetLogger: %q", got)
+			}
+
+			// 之后调用InitLogSetting重新使用按配置创建的logger
+			initTestLogger(t, &LogConfig{Dir: dir, Level: "info"})
+			Info("configured again")
+			_ = Sync()
+			if !strings.Contains(readLog(t, filepath.Join(dir, "test.log")), "configured again") || GetLogConf().ExternalLogger {
+				t.Error("InitLogSetting did not replace the external logger")
+			}
+		})
+	}
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+	initTestLogger(t, &LogConfig{Level: "info"})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				Info("concurrent")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				SetLogger(zap.NewNop())
+			}
+		}()
+	}
+	wg.Wait()
+}
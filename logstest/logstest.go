@@ -0,0 +1,74 @@
+// Package logstest 在测试中检查和显示默认logger输出的日志
+package logstest
+
+import (
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FailOnError 测试结束时如果默认logger输出过error及以上级别的日志, 则标记测试失败并列出这些日志
+//
+//	logstest.FailOnError(t)
+func FailOnError(t testing.TB) {
+	t.Helper()
+
+	counter := &errorCounter{}
+	remove := logs.AddCore(counter)
+	t.Cleanup(func() {
+		remove()
+		if msgs := counter.messages(); len(msgs) > 0 {
+			t.Errorf("logs: %d error entries were logged:\n%s", len(msgs), strings.Join(msgs, "\n"))
+		}
+	})
+}
+
+// UseTestingT 将默认logger的日志输出到t.Log, 只在测试失败或使用-v时显示, 返回的函数恢复调用前的日志状态
+//
+//	defer logstest.UseTestingT(t)()
+func UseTestingT(t testing.TB) func() {
+	r := logs.Snapshot()
+	logs.SetLogger(zaptest.NewLogger(t, zaptest.Level(zapcore.DebugLevel)))
+	return r.Restore
+}
+
+// errorCounter 记录error及以上级别的日志
+type errorCounter struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (c *errorCounter) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.ErrorLevel
+}
+
+func (c *errorCounter) With([]zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *errorCounter) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *errorCounter) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, ent.Level.CapitalString()+" "+ent.Message)
+	return nil
+}
+
+func (c *errorCounter) Sync() error {
+	return nil
+}
+
+func (c *errorCounter) messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.msgs...)
+}
@@ -0,0 +1,126 @@
+package logstest
+
+import (
+	"fmt"
+	"github.com/xpfo-go/logs"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTB 记录Errorf和Logf的输出, Cleanup注册的函数由cleanup调用
+type fakeTB struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	logs     []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Name() string { return "fake" }
+
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Logf(format string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) cleanup() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func initLogs(t *testing.T) {
+	t.Helper()
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailOnError(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        func()
+		wantFailed bool
+		wantMsgs   []string
+	}{
+		{"no errors", func() { logs.Info("fine"); logs.Warn("careful") }, false, nil},
+		{"error", func() { logs.Error("broken") }, true, []string{"ERROR broken"}},
+		{"named and dpanic", func() { logs.Named("db").Errorw("query failed"); logs.DPanic("odd") }, true, []string{"ERROR query failed", "DPANIC odd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initLogs(t)
+			tb := &fakeTB{}
+			FailOnError(tb)
+			tt.log()
+			tb.cleanup()
+			if failed := len(tb.errors) > 0; failed != tt.wantFailed {
+				t.Fatalf("failed = %v, want %v: %v", failed, tt.wantFailed, tb.errors)
+			}
+			for _, msg := range tt.wantMsgs {
+				if !strings.Contains(tb.errors[0], msg) {
+					t.Errorf("failure = %q, want %q listed", tb.errors[0], msg)
+				}
+			}
+			// 清理后不再记录
+			logs.Error("after cleanup")
+			if len(tb.errors) > 1 {
+				t.Errorf("errors after cleanup = %v", tb.errors)
+			}
+		})
+	}
+}
+
+func TestFailOnErrorConcurrent(t *testing.T) {
+	initLogs(t)
+	tb := &fakeTB{}
+	FailOnError(tb)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logs.Errorf("error %d", i)
+		}(i)
+	}
+	wg.Wait()
+	tb.cleanup()
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "8 error entries") {
+		t.Errorf("errors = %v, want 8 entries reported", tb.errors)
+	}
+}
+
+func TestUseTestingT(t *testing.T) {
+	initLogs(t)
+	tb := &fakeTB{}
+	restore := UseTestingT(tb)
+	logs.Infow("to testing", "k", "v")
+	logs.Named("db").Debug("debug line")
+	restore()
+	logs.Info("after restore")
+
+	got := strings.Join(tb.logs, "\n")
+	for _, want := range []string{"to testing", `"k": "v"`, "debug line"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("t.Log output = %q, want %q", got, want)
+		}
+	}
+	if strings.Contains(got, "after restore") {
+		t.Errorf("t.Log output = %q, want nothing after restore", got)
+	}
+	if logs.GetLogConf().ExternalLogger {
+		t.Error("ExternalLogger still set after restore")
+	}
+}
@@ -0,0 +1,175 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	namedLevelsMu sync.RWMutex
+	namedLevels   = map[string]zapcore.Level{}
+	// namedLevelsGen 运行时设置的模块级别每次变化时加1, 使已解析的级别失效
+	namedLevelsGen uint64
+)
+
+// Named 获取指定名称的logger, 名称会输出在日志中, 与默认Logger使用相同的输出和日志级别, 重新初始化后使用新的输出
+// 多次调用Named时名称以.连接, 如scheduler.retry
+func Named(name string) *Logger {
+	return live().Named(name)
+}
+
+// SetModuleLevel 在运行时设置模块(Named的名称)的日志级别, 可以高于或低于全局日志级别, 对所有Logger生效
+// 名称为a.b的logger依次使用a.b、a的设置, 都未设置时使用LogConfig.Levels, 再使用全局日志级别
+// level为空时取消该模块的设置
+func SetModuleLevel(name, level string) error {
+	var lvl zapcore.Level
+	if level != "" {
+		var err error
+		if lvl, err = parseLevel(level); err != nil {
+			return fmt.Errorf("logs: invalid level %q, accepted values: %s", level, levelNames)
+		}
+	}
+
+	namedLevelsMu.Lock()
+	defer namedLevelsMu.Unlock()
+	if level == "" {
+		delete(namedLevels, name)
+	} else {
+		namedLevels[name] = lvl
+	}
+	atomic.AddUint64(&namedLevelsGen, 1)
+	return nil
+}
+
+// SetNamedLevel 与SetModuleLevel相同, 无法识别的level返回错误
+func SetNamedLevel(name, level string) error {
+	return SetModuleLevel(name, level)
+}
+
+// resolvedLevel 模块解析后的日志级别, ok为false时使用全局日志级别
+type resolvedLevel struct {
+	lvl zapcore.Level
+	ok  bool
+}
+
+// moduleLevels Logger的模块日志级别, 每个名称只在首次输出和设置变化后解析一次
+type moduleLevels struct {
+	// config LogConfig.Levels中的设置
+	config map[string]zapcore.Level
+
+	mu       sync.RWMutex
+	gen      uint64
+	resolved map[string]resolvedLevel
+	// minLevel 所有模块设置中最低的级别
+	minLevel zapcore.Level
+}
+
+func newModuleLevels(config map[string]zapcore.Level) *moduleLevels {
+	return &moduleLevels{config: config}
+}
+
+func (m *moduleLevels) resolve(name string) (zapcore.Level, bool) {
+	gen := atomic.LoadUint64(&namedLevelsGen)
+	m.mu.RLock()
+	r, hit := m.resolved[name]
+	valid := m.resolved != nil && m.gen == gen
+	m.mu.RUnlock()
+	if valid && hit {
+		return r.lvl, r.ok
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh(gen)
+	r = m.lookup(name)
+	m.resolved[name] = r
+	return r.lvl, r.ok
+}
+
+func (m *moduleLevels) min() zapcore.Level {
+	gen := atomic.LoadUint64(&namedLevelsGen)
+	m.mu.RLock()
+	lvl, valid := m.minLevel, m.resolved != nil && m.gen == gen
+	m.mu.RUnlock()
+	if valid {
+		return lvl
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh(gen)
+	return m.minLevel
+}
+
+// refresh 设置变化后清空已解析的级别, 调用时需持有m.mu
+func (m *moduleLevels) refresh(gen uint64) {
+	if m.resolved != nil && m.gen == gen {
+		return
+	}
+	m.gen = gen
+	m.resolved = map[string]resolvedLevel{}
+	m.minLevel = zapcore.InvalidLevel
+	for _, lvl := range m.config {
+		if lvl < m.minLevel {
+			m.minLevel = lvl
+		}
+	}
+	namedLevelsMu.RLock()
+	for _, lvl := range namedLevels {
+		if lvl < m.minLevel {
+			m.minLevel = lvl
+		}
+	}
+	namedLevelsMu.RUnlock()
+}
+
+// lookup 从最具体的名称开始查找设置, 运行时的设置优先
+func (m *moduleLevels) lookup(name string) resolvedLevel {
+	namedLevelsMu.RLock()
+	defer namedLevelsMu.RUnlock()
+	for {
+		if lvl, ok := namedLevels[name]; ok {
+			return resolvedLevel{lvl, true}
+		}
+		if lvl, ok := m.config[name]; ok {
+			return resolvedLevel{lvl, true}
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return resolvedLevel{}
+		}
+		name = name[:i]
+	}
+}
+
+// namedLevelCore 按logger名称(模块)的日志级别过滤, 未设置的模块使用全局日志级别
+type namedLevelCore struct {
+	zapcore.Core
+	level  zap.AtomicLevel
+	levels *moduleLevels
+}
+
+func (c namedLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl) || lvl >= c.levels.min()
+}
+
+func (c namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return namedLevelCore{c.Core.With(fields), c.level, c.levels}
+}
+
+func (c namedLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	threshold := c.level.Level()
+	if ent.LoggerName != "" {
+		if lvl, ok := c.levels.resolve(ent.LoggerName); ok {
+			threshold = lvl
+		}
+	}
+	if ent.Level < threshold {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
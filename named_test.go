@@ -0,0 +1,112 @@
+package logs
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDerivedLoggersFollowReinit(t *testing.T) {
+	tests := []struct {
+		name   string
+		derive func() *Logger
+	}{
+		{"Named", func() *Logger { return Named("worker") }},
+		{"With", func() *Logger { return With("request_id", "r1") }},
+		{"WithFields", func() *Logger { return WithFields(map[string]interface{}{"user": 1}) }},
+		{"WithError", func() *Logger { return WithError(errors.New("boom")) }},
+		{"Skip", func() *Logger { return Skip(0) }},
+		{"Named.With", func() *Logger { return Named("worker").With("k", "v") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{})
+			lg := tt.derive()
+
+			dir := t.TempDir()
+			if err := InitLogSetting(&LogConfig{Dir: dir, FileName: "after", DisableConsole: true}); err != nil {
+				t.Fatal(err)
+			}
+			lg.Info("after reinit")
+			_ = Sync()
+
+			got := readLog(t, filepath.Join(dir, "after.log"))
+			if !strings.Contains(got, "after reinit") {
+				t.Fatalf("new log file = %q, want the derived logger's entry", got)
+			}
+			if !strings.Contains(got, "named_test.go") {
+				t.Errorf("caller in %q, want named_test.go", got)
+			}
+		})
+	}
+}
+
+func TestNamedKeepsNameAndFields(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{})
+	Named("scheduler").Named("retry").With("job", 7).Warn("retrying")
+	_ = Sync()
+
+	got := readLog(t, filepath.Join(dir, "test.log"))
+	for _, want := range []string{"scheduler.retry", `"job": 7`, "retrying"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log file = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestSetModuleLevel(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{Level: "info"})
+	tests := []struct {
+		name    string
+		module  string
+		level   string
+		write   func(lg *Logger)
+		want    string
+		wantErr bool
+	}{
+		{"lower than global", "db", "debug", func(lg *Logger) { lg.Debug("db debug") }, "db debug", false},
+		{"child inherits", "db", "debug", func(lg *Logger) { lg.Named("pool").Debug("pool debug") }, "pool debug", false},
+		{"invalid", "db", "verbose", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetModuleLevel(tt.module, tt.level)
+			defer SetModuleLevel(tt.module, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetModuleLevel error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.write == nil {
+				return
+			}
+			tt.write(Named(tt.module))
+			Named("other").Debug("other debug")
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("log file = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(got, "other debug") {
+				t.Errorf("log file = %q, other module should stay at info", got)
+			}
+		})
+	}
+}
+
+func TestSetNamedLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		wantErr bool
+	}{
+		{"warn", false},
+		{"", false},
+		{"verbose", true},
+	}
+	for _, tt := range tests {
+		err := SetNamedLevel("db", tt.level)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetNamedLevel(%q) error = %v, wantErr %v", tt.level, err, tt.wantErr)
+		}
+	}
+	_ = SetModuleLevel("db", "")
+}
@@ -0,0 +1,88 @@
+package logs
+
+import "fmt"
+
+// Option New使用的配置项, 按顺序应用, 后面的配置项覆盖前面的
+type Option func(*LogConfig)
+
+// New 在默认配置上应用opts并初始化日志, 与InitLogSetting相同, 配置无效或互相冲突时返回错误
+//
+//	err := logs.New(logs.WithFileName("api"), logs.WithLevel("info"), logs.WithDir("/var/log/api"), logs.WithJSON())
+func New(opts ...Option) error {
+	c := defaultLogConfig()
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.DisableFile && c.DisableConsole {
+		return fmt.Errorf("logs: WithConsoleOnly and WithFileOnly cannot be used together")
+	}
+	if c.DisableFile && c.Dir != "" {
+		return fmt.Errorf("logs: WithDir cannot be used with WithConsoleOnly")
+	}
+	return InitLogSetting(c)
+}
+
+// WithFileName 日志文件名
+func WithFileName(name string) Option {
+	return func(c *LogConfig) { c.FileName = name }
+}
+
+// WithLevel 日志级别 debug info warn error dpanic panic fatal
+func WithLevel(level string) Option {
+	return func(c *LogConfig) { c.Level = level }
+}
+
+// WithMaxAge 日志文件保存天数
+func WithMaxAge(days int) Option {
+	return func(c *LogConfig) { c.MaxAge = days }
+}
+
+// WithMaxSize 单个日志文件的最大大小 单位MB
+func WithMaxSize(mb int) Option {
+	return func(c *LogConfig) { c.MaxSize = mb }
+}
+
+// WithMaxBackups 保留的轮转文件个数
+func WithMaxBackups(n int) Option {
+	return func(c *LogConfig) { c.MaxBackups = n }
+}
+
+// WithCompress 轮转后的文件使用gzip压缩
+func WithCompress() Option {
+	return func(c *LogConfig) { c.Compress = true }
+}
+
+// WithDir 日志目录
+func WithDir(dir string) Option {
+	return func(c *LogConfig) { c.Dir = dir }
+}
+
+// WithJSON 日志文件使用json格式
+func WithJSON() Option {
+	return func(c *LogConfig) { c.Encoding = "json" }
+}
+
+// WithConsoleOnly 只输出到stdout和stderr, 不写日志文件
+func WithConsoleOnly() Option {
+	return func(c *LogConfig) { c.DisableFile = true }
+}
+
+// WithFileOnly 只写日志文件, 不输出到stdout和stderr
+func WithFileOnly() Option {
+	return func(c *LogConfig) { c.DisableConsole = true }
+}
+
+// WithTimeLayout 日志的时间格式
+func WithTimeLayout(layout string) Option {
+	return func(c *LogConfig) { c.TimeLayout = layout }
+}
+
+// WithCallerSkip 调用位置额外跳过的调用层数, 对本包再做一层封装时使用
+func WithCallerSkip(n int) Option {
+	return func(c *LogConfig) { c.CallerSkip = n }
+}
+
+// WithTimezone 日志时间使用的时区 如Asia/Shanghai
+func WithTimezone(name string) Option {
+	return func(c *LogConfig) { c.Timezone = name }
+}
@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		check   func(c *LogConfig) bool
+		wantErr string
+	}{
+		{"defaults", nil, func(c *LogConfig) bool { return c.FileName == "log" && c.Level == "debug" && c.MaxAge == 20 }, ""},
+		{"composed", []Option{WithFileName("api"), WithLevel("info"), WithMaxAge(7), WithMaxSize(10), WithMaxBackups(3), WithCompress(), WithJSON()},
+			func(c *LogConfig) bool {
+				return c.FileName == "api" && c.Level == "info" && c.MaxAge == 7 && c.MaxSize == 10 && c.MaxBackups == 3 && c.Compress && c.Encoding == "json"
+			}, ""},
+		{"later overrides earlier", []Option{WithLevel("info"), WithLevel("warn")}, func(c *LogConfig) bool { return c.Level == "warn" }, ""},
+		{"time options", []Option{WithTimeLayout(time.RFC3339), WithTimezone("UTC"), WithCallerSkip(1)},
+			func(c *LogConfig) bool {
+				return c.TimeLayout == time.RFC3339 && c.Timezone == "UTC" && c.CallerSkip == 1
+			}, ""},
+		{"invalid level", []Option{WithLevel("loud")}, nil, "invalid Level"},
+		// 测试中总是使用WithFileOnly
+		{"console and file only", []Option{WithConsoleOnly()}, nil, "cannot be used together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{})
+			err := New(append([]Option{WithDir(dir), WithFileOnly()}, tt.opts...)...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New error = %v, want %q", err, tt.wantErr)
+				}
+				if GetLogConf().FileName != "test" {
+					t.Errorf("failed New changed the config to %+v", GetLogConf())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if c := GetLogConf(); !tt.check(c) || c.Dir != dir || !c.DisableConsole {
+				t.Errorf("GetLogConf() = %+v", c)
+			}
+		})
+	}
+}
+
+func TestNewDirOnConsoleOnly(t *testing.T) {
+	initTestLogger(t, &LogConfig{})
+	if err := New(WithConsoleOnly(), WithDir(t.TempDir())); err == nil || !strings.Contains(err.Error(), "WithDir") {
+		t.Errorf("New error = %v, want the WithDir conflict", err)
+	}
+}
@@ -0,0 +1,86 @@
+package logs
+
+import (
+	"context"
+	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecoverWithContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		extras  []interface{}
+		want    []string
+		notWant string
+	}{
+		{"context fields", ContextWithFields(context.Background(), "request_id", "r-1"), nil, []string{"boom", `"request_id": "r-1"`, "frame 0:"}, ""},
+		{"nil context", nil, nil, []string{"boom", "frame 0:"}, "request_id"},
+		{"extras", context.Background(), []interface{}{"payload"}, []string{"boom", "EXTRAS#0"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{})
+			func() {
+				defer RecoverWithContext(tt.ctx, tt.extras...)
+				panic("boom")
+			}()
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test_err.log"))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("log missing %q:\n%s", want, got)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("log contains %q:\n%s", tt.notWant, got)
+			}
+		})
+	}
+}
+
+func TestPanicWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		kv      []interface{}
+		written bool
+		fields  map[string]interface{}
+	}{
+		{"fields", "info", []interface{}{"order", "o-1", "state", 3}, true, map[string]interface{}{"order": "o-1", "state": int64(3)}},
+		{"no fields", "info", nil, true, nil},
+		{"filtered still panics", "fatal", []interface{}{"order", "o-1"}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, tt.level)
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				PanicWith("invalid state", tt.kv...)
+			}()
+
+			if recovered != "invalid state" {
+				t.Errorf("recovered = %v, want invalid state", recovered)
+			}
+			entries := recorded.FilterMessage("invalid state").All()
+			if got := len(entries) == 1; got != tt.written {
+				t.Fatalf("entries = %d, want written %v", len(entries), tt.written)
+			}
+			if !tt.written {
+				return
+			}
+			e := entries[0]
+			if e.Level != zapcore.PanicLevel || !strings.HasSuffix(e.Caller.File, "panic_test.go") {
+				t.Errorf("entry = %s at %s, want panic at panic_test.go", e.Level, e.Caller.File)
+			}
+			for k, want := range tt.fields {
+				if got := e.ContextMap()[k]; got != want {
+					t.Errorf("%s = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package logs
+
+import "go.uber.org/zap/zapcore"
+
+// Print 以info级别输出日志, 与标准库log的Print兼容
+func Print(v ...interface{}) {
+	l().Info(v...)
+}
+
+func Printf(format string, v ...interface{}) {
+	l().Infof(format, v...)
+}
+
+// Println 以info级别输出日志, 参数间以空格分隔, 不输出末尾的换行
+func Println(v ...interface{}) {
+	l().Infoln(v...)
+}
+
+// Printer 以指定级别输出日志, 实现Print Printf Println, 可传给只接受标准库log形式的库
+type Printer struct {
+	level zapcore.Level
+}
+
+// StdPrinter 获取以level级别输出日志的Printer, 调用会转发到默认logger, level无效时使用info
+//
+//	retry.WithLogger(logs.StdPrinter("warn"))
+func StdPrinter(level string) Printer {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		lvl = zapcore.InfoLevel
+	}
+	return Printer{level: lvl}
+}
+
+func (p Printer) Print(v ...interface{}) {
+	l().Log(p.level, v...)
+}
+
+func (p Printer) Printf(format string, v ...interface{}) {
+	l().Logf(p.level, format, v...)
+}
+
+func (p Printer) Println(v ...interface{}) {
+	l().Logln(p.level, v...)
+}
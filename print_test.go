@@ -0,0 +1,51 @@
+package logs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stdPrinter 标准库log形式的接口, 即第三方库接受的logger
+type stdPrinter interface {
+	Print(v ...interface{})
+	Printf(format string, v ...interface{})
+	Println(v ...interface{})
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func()
+		want  string
+		msg   string
+	}{
+		{"Print", func() { Print("a", "b") }, "\tINFO\t", "\tab"},
+		{"Printf", func() { Printf("%s %d", "a", 1) }, "\tINFO\t", "\ta 1"},
+		{"Println", func() { Println("a", "b") }, "\tINFO\t", "\ta b"},
+		{"StdPrinter warn", func() { stdPrinter(StdPrinter("warn")).Println("a", "b") }, "\tWARN\t", "\ta b"},
+		{"StdPrinter error Printf", func() { stdPrinter(StdPrinter("error")).Printf("%s", "a") }, "\tERROR\t", "\ta"},
+		{"StdPrinter invalid level", func() { stdPrinter(StdPrinter("loud")).Print("a") }, "\tINFO\t", "\ta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", StacktraceLevel: "disabled"})
+			tt.write()
+			Info("next entry")
+			_ = Sync()
+
+			lines := strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n")
+			if len(lines) < 3 {
+				t.Fatalf("lines = %q, want the entry followed by the next entry", lines)
+			}
+			// Println不会多输出一个换行, 下一条日志紧接在下一行
+			entry, next := lines[len(lines)-3], lines[len(lines)-2]
+			if !strings.Contains(entry, tt.want) || !strings.Contains(entry, tt.msg) || !strings.Contains(entry, "print_test.go") {
+				t.Errorf("entry = %q, want %q and %q with the caller", entry, tt.want, tt.msg)
+			}
+			if !strings.Contains(next, "next entry") {
+				t.Errorf("line after the entry = %q, want the next entry", next)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package logs
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const defaultProgressInterval = 10 * time.Second
+
+// ProgressLogger 批处理任务的进度日志, 通过Progress创建
+type ProgressLogger struct {
+	// Interval 两次进度日志的最小间隔
+	Interval time.Duration
+
+	mu       sync.Mutex
+	total    int
+	start    time.Time
+	last     time.Time
+	finished bool
+}
+
+// Progress 创建总数为total的进度日志, 默认每10秒最多输出一次
+func Progress(total int) *ProgressLogger {
+	return &ProgressLogger{
+		Interval: defaultProgressInterval,
+		total:    total,
+		start:    time.Now(),
+	}
+}
+
+// Update 更新已完成数量, 距上次输出超过Interval时输出百分比、速率和预计剩余时间
+// 全部完成时输出汇总日志, 之后的调用不再输出
+func (p *ProgressLogger) Update(done int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished {
+		return
+	}
+	now := time.Now()
+	elapsed := now.Sub(p.start)
+	rate := progressRate(done, elapsed)
+	if done >= p.total {
+		p.finished = true
+		l().Infow("progress completed",
+			"total", p.total,
+			"elapsed", elapsed,
+			"rate", round2(rate),
+		)
+		return
+	}
+	if now.Sub(p.last) < p.Interval {
+		return
+	}
+	p.last = now
+
+	kv := []interface{}{
+		"done", done,
+		"total", p.total,
+		"percent", progressPercent(done, p.total),
+		"rate", round2(rate),
+	}
+	if rate > 0 {
+		eta := time.Duration(float64(p.total-done) / rate * float64(time.Second))
+		kv = append(kv, "eta", eta.Round(time.Second))
+	}
+	l().Infow("progress", kv...)
+}
+
+// progressRate 每秒完成数量, elapsed不大于0时为0, 避免输出Inf和NaN
+func progressRate(done int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(done) / elapsed.Seconds()
+}
+
+// progressPercent 完成百分比, total不大于0时为100
+func progressPercent(done, total int) float64 {
+	if total <= 0 {
+		return 100
+	}
+	return round2(float64(done) * 100 / float64(total))
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
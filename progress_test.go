@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProgressLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		elapsed time.Duration
+		done    int
+		wantMsg string
+		want    map[string]interface{}
+	}{
+		{"halfway", 10, 10 * time.Second, 5, "progress", map[string]interface{}{"percent": 50.0, "rate": 0.5, "eta": 10 * time.Second}},
+		{"clock moved back", 10, -time.Hour, 5, "progress", map[string]interface{}{"percent": 50.0, "rate": 0.0}},
+		{"zero total", 0, 0, 0, "progress completed", map[string]interface{}{"total": int64(0), "rate": 0.0}},
+		{"completed", 10, 10 * time.Second, 10, "progress completed", map[string]interface{}{"total": int64(10), "rate": 1.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "info")
+			p := Progress(tt.total)
+			p.start = time.Now().Add(-tt.elapsed)
+			p.Update(tt.done)
+
+			entries := recorded.All()
+			if len(entries) != 1 || entries[0].Message != tt.wantMsg {
+				t.Fatalf("entries = %v, want one %q", entries, tt.wantMsg)
+			}
+			fields := entries[0].ContextMap()
+			for k, v := range fields {
+				if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+					t.Errorf("%s = %v, want a finite number", k, f)
+				}
+			}
+			if _, ok := tt.want["eta"]; !ok {
+				if eta, ok := fields["eta"]; ok {
+					t.Errorf("eta = %v, want it omitted", eta)
+				}
+			}
+			for k, want := range tt.want {
+				if got := fields[k]; got != want {
+					t.Errorf("%s = %v (%T), want %v", k, got, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProgressLoggerInterval(t *testing.T) {
+	recorded := observeDefault(t, "info")
+	p := Progress(10)
+	for i := 1; i <= 10; i++ {
+		p.Update(i)
+	}
+	p.Update(10)
+
+	// 第一次更新和完成时各输出一次, 完成后不再输出
+	if got := recorded.FilterMessage("progress").Len(); got != 1 {
+		t.Errorf("progress entries = %d, want 1", got)
+	}
+	if got := recorded.FilterMessage("progress completed").Len(); got != 1 {
+		t.Errorf("completed entries = %d, want 1", got)
+	}
+}
+
+func TestProgressRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		done, total int
+		elapsed     time.Duration
+		rate        float64
+		percent     float64
+	}{
+		{"normal", 5, 10, 10 * time.Second, 0.5, 50},
+		{"zero elapsed", 5, 10, 0, 0, 50},
+		{"zero done and elapsed", 0, 10, 0, 0, 0},
+		{"negative elapsed", 5, 10, -time.Second, 0, 50},
+		{"zero total", 0, 0, 0, 0, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progressRate(tt.done, tt.elapsed); got != tt.rate {
+				t.Errorf("progressRate(%d, %s) = %v, want %v", tt.done, tt.elapsed, got, tt.rate)
+			}
+			if got := progressPercent(tt.done, tt.total); got != tt.percent {
+				t.Errorf("progressPercent(%d, %d) = %v, want %v", tt.done, tt.total, got, tt.percent)
+			}
+		})
+	}
+}
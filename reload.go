@@ -0,0 +1,47 @@
+package logs
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// EnableSIGHUPReload 收到SIGHUP时重新读取configPath并初始化日志, 然后轮转日志文件,
+// 配合logrotate等外部工具移动日志文件后, 新的日志写入新文件. 返回的函数取消处理
+// 读取或初始化失败时保持原来的配置并以error级别输出原因
+//
+//	stop := logs.EnableSIGHUPReload("/etc/myapp/logs.yaml")
+//	defer stop()
+func EnableSIGHUPReload(configPath string) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				reloadConfig(configPath)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+func reloadConfig(configPath string) {
+	if err := InitLogSettingFromFile(configPath); err != nil {
+		l().Errorw("reload logging config failed", "file", configPath, "error", err)
+	}
+	if err := std().Rotate(); err != nil {
+		l().Errorw("rotate log files failed", "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build !windows
+// +build !windows
+
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSIGHUPReload(t *testing.T) {
+	tests := []struct {
+		name      string
+		reloaded  string
+		wantLevel string
+		wantLog   string
+	}{
+		{"new level", "level: debug\n", "debug", ""},
+		{"invalid config keeps level", "level: loud\n", "info", "reload logging config failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			path := filepath.Join(t.TempDir(), "logs.yaml")
+			base := fmt.Sprintf("file_name: test\ndir: %s\ndisable_console: true\n", dir)
+			if err := os.WriteFile(path, []byte(base+tt.reloaded), 0644); err != nil {
+				t.Fatal(err)
+			}
+			stop := EnableSIGHUPReload(path)
+			defer stop()
+
+			// 重新加载时其他goroutine输出的日志不丢失也不重复
+			const n = 2000
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < n; i++ {
+					Warnf("seq %d;", i)
+				}
+			}()
+			if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+			// 等待重新加载后轮转出的文件
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				if rotated, _ := filepath.Glob(filepath.Join(dir, "test-*.log")); len(rotated) > 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("log file not rotated after SIGHUP")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := GetLevel(); got != tt.wantLevel {
+				t.Errorf("GetLevel = %q, want %q", got, tt.wantLevel)
+			}
+
+			_ = Sync()
+			var all string
+			entries, _ := os.ReadDir(dir)
+			for _, e := range entries {
+				if !strings.Contains(e.Name(), "_err") {
+					all += readLog(t, filepath.Join(dir, e.Name()))
+				}
+			}
+			for i := 0; i < n; i++ {
+				if c := strings.Count(all, fmt.Sprintf("seq %d;", i)); c != 1 {
+					t.Fatalf("seq %d logged %d times, want 1", i, c)
+				}
+			}
+			if tt.wantLog != "" && !strings.Contains(all, tt.wantLog) {
+				t.Errorf("logs missing %q", tt.wantLog)
+			}
+		})
+	}
+}
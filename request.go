@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type requestLoggerKey struct{}
+
+// RequestLogger 请求范围的logger, 统计请求期间各级别输出的日志条数, Finish时输出汇总
+type RequestLogger struct {
+	*zap.SugaredLogger
+	name   string
+	start  time.Time
+	counts *levelCounts
+	// finished Finish已调用时为1
+	finished int32
+	// summary 输出汇总日志, 不计入统计
+	summary *zap.SugaredLogger
+}
+
+// StartRequest 创建请求范围的logger并保存到context中, 日志附加ctx中的字段和keysAndValues
+// 请求结束时调用Finish输出汇总
+//
+//	ctx, rl := logs.StartRequest(ctx, "GET /orders", "request_id", id)
+//	defer rl.Finish()
+func StartRequest(ctx context.Context, name string, keysAndValues ...interface{}) (context.Context, *RequestLogger) {
+	base := userLogger().With(contextFields(ctx)...).With(keysAndValues...)
+	counts := &levelCounts{}
+	r := &RequestLogger{
+		SugaredLogger: base.Desugar().WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return countingCore{c, counts}
+		})).Sugar(),
+		name:    name,
+		start:   time.Now(),
+		counts:  counts,
+		summary: base.Desugar().WithOptions(zap.AddCallerSkip(1)).Sugar(),
+	}
+	return context.WithValue(ctx, requestLoggerKey{}, r), r
+}
+
+// RequestFromContext 获取StartRequest保存在context中的logger, 不存在时返回nil
+func RequestFromContext(ctx context.Context) *RequestLogger {
+	r, _ := ctx.Value(requestLoggerKey{}).(*RequestLogger)
+	return r
+}
+
+// Finish 输出请求的汇总日志, 包括耗时和各级别的日志条数, 多次调用只输出一次
+func (r *RequestLogger) Finish() {
+	// 不使用sync.Once, 保证汇总日志的调用位置为Finish的调用方
+	if !atomic.CompareAndSwapInt32(&r.finished, 0, 1) {
+		return
+	}
+	debug, info, warn, errs := r.counts.get(zapcore.DebugLevel), r.counts.get(zapcore.InfoLevel),
+		r.counts.get(zapcore.WarnLevel), r.counts.errors()
+	msg := "request completed"
+	var parts []string
+	if warn > 0 {
+		parts = append(parts, plural(warn, "warning"))
+	}
+	if errs > 0 {
+		parts = append(parts, plural(errs, "error"))
+	}
+	if len(parts) > 0 {
+		msg += " with " + strings.Join(parts, ", ")
+	}
+	r.summary.Infow(msg,
+		"request", r.name,
+		"elapsed", time.Since(r.start),
+		"debug_count", debug,
+		"info_count", info,
+		"warn_count", warn,
+		"error_count", errs,
+	)
+}
+
+func plural(n int64, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// levelCounts 各级别的日志条数
+type levelCounts struct {
+	n [zapcore.FatalLevel - zapcore.DebugLevel + 1]int64
+}
+
+func (c *levelCounts) inc(lvl zapcore.Level) {
+	if lvl >= zapcore.DebugLevel && lvl <= zapcore.FatalLevel {
+		atomic.AddInt64(&c.n[lvl-zapcore.DebugLevel], 1)
+	}
+}
+
+func (c *levelCounts) get(lvl zapcore.Level) int64 {
+	return atomic.LoadInt64(&c.n[lvl-zapcore.DebugLevel])
+}
+
+// errors error及以上级别的日志条数
+func (c *levelCounts) errors() int64 {
+	var n int64
+	for lvl := zapcore.ErrorLevel; lvl <= zapcore.FatalLevel; lvl++ {
+		n += c.get(lvl)
+	}
+	return n
+}
+
+// countingCore 统计会被输出的日志条数
+type countingCore struct {
+	zapcore.Core
+	counts *levelCounts
+}
+
+func (c countingCore) With(fields []zapcore.Field) zapcore.Core {
+	return countingCore{c.Core.With(fields), c.counts}
+}
+
+func (c countingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	next := c.Core.Check(ent, ce)
+	if next != nil && next != ce {
+		c.counts.inc(ent.Level)
+	}
+	return next
+}
@@ -0,0 +1,93 @@
+package logs
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStartRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		log     func(r *RequestLogger)
+		wantMsg string
+		counts  map[string]int64
+	}{
+		{"no entries", "debug", func(r *RequestLogger) {}, "request completed",
+			map[string]int64{"debug_count": 0, "info_count": 0, "warn_count": 0, "error_count": 0}},
+		{"each level", "debug", func(r *RequestLogger) {
+			r.Debug("d")
+			r.Info("i")
+			r.Infow("i", "k", "v")
+			r.Warn("w")
+			r.Error("e")
+		}, "request completed with 1 warning, 1 error",
+			map[string]int64{"debug_count": 1, "info_count": 2, "warn_count": 1, "error_count": 1}},
+		{"plural", "debug", func(r *RequestLogger) {
+			r.Warn("w")
+			r.Warn("w")
+			r.Error("e")
+			r.DPanic("e")
+		}, "request completed with 2 warnings, 2 errors",
+			map[string]int64{"warn_count": 2, "error_count": 2}},
+		{"filtered entries not counted", "info", func(r *RequestLogger) {
+			r.Debug("d")
+			r.Debug("d")
+			r.Error("e")
+		}, "request completed with 1 error",
+			map[string]int64{"debug_count": 0, "info_count": 0, "error_count": 1}},
+		{"derived loggers counted", "debug", func(r *RequestLogger) {
+			r.With("step", 1).Warn("w")
+			r.Named("db").Info("i")
+		}, "request completed with 1 warning",
+			map[string]int64{"info_count": 1, "warn_count": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, tt.level)
+			ctx, r := StartRequest(ContextWithFields(context.Background(), "user", "u-1"), "GET /orders", "request_id", "r-1")
+			if got := RequestFromContext(ctx); got != r {
+				t.Errorf("RequestFromContext = %p, want %p", got, r)
+			}
+			tt.log(r)
+			r.Finish()
+			r.Finish()
+
+			summaries := recorded.FilterMessageSnippet("request completed").All()
+			if len(summaries) != 1 {
+				t.Fatalf("summaries = %d, want 1", len(summaries))
+			}
+			s := summaries[0]
+			if s.Message != tt.wantMsg {
+				t.Errorf("summary = %q, want %q", s.Message, tt.wantMsg)
+			}
+			if !strings.HasSuffix(s.Caller.File, "request_test.go") {
+				t.Errorf("summary caller = %s, want request_test.go", s.Caller.File)
+			}
+			fields := s.ContextMap()
+			if fields["request"] != "GET /orders" || fields["request_id"] != "r-1" || fields["user"] != "u-1" {
+				t.Errorf("summary fields = %v, want request, request_id and user", fields)
+			}
+			if _, ok := fields["elapsed"]; !ok {
+				t.Errorf("summary fields = %v, want elapsed", fields)
+			}
+			for k, want := range tt.counts {
+				if got := fields[k]; got != want {
+					t.Errorf("%s = %v, want %d", k, got, want)
+				}
+			}
+			for _, e := range recorded.All() {
+				if e.ContextMap()["request_id"] != "r-1" {
+					t.Errorf("entry %q fields = %v, want request_id", e.Message, e.ContextMap())
+				}
+			}
+		})
+	}
+}
+
+func TestRequestFromContextMissing(t *testing.T) {
+	if got := RequestFromContext(context.Background()); got != nil {
+		t.Errorf("RequestFromContext = %v, want nil", got)
+	}
+}
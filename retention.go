@@ -0,0 +1,61 @@
+package logs
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	retentionMu sync.Mutex
+	// retentionTimer 恢复保存时间的定时器
+	retentionTimer *time.Timer
+	// retentionOutputs 延长了保存时间的输出, 重新初始化后新的输出使用各自的配置
+	retentionOutputs *outputs
+)
+
+// ExtendRetention 在接下来的d时间内延长日志文件的保存时间, 期间MaxAge增加d对应的天数(向上取整),
+// 不按MaxBackups清理, 到期后恢复原来的MaxAge和MaxBackups. 用于发布、迁移等操作前保留更多的日志
+// 直接修改当前日志文件的配置, 不重新初始化, GetLogConf返回的配置不变
+// 再次调用时从原来的配置重新计算, d<=0时立即恢复, MaxAge和MaxBackups都为0(不清理)时不做任何事
+func ExtendRetention(d time.Duration) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+
+	if retentionTimer != nil {
+		retentionTimer.Stop()
+		restoreRetention(retentionOutputs)
+		retentionTimer, retentionOutputs = nil, nil
+	}
+	o := std().outputs
+	if o.conf == nil || o.conf.MaxAge <= 0 && o.conf.MaxBackups <= 0 || d <= 0 {
+		return
+	}
+
+	maxAge := o.conf.MaxAge
+	if maxAge > 0 {
+		maxAge += int((d + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	o.setRetention(maxAge, 0)
+	retentionOutputs = o
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		retentionMu.Lock()
+		defer retentionMu.Unlock()
+		if retentionTimer != timer {
+			return
+		}
+		restoreRetention(retentionOutputs)
+		retentionTimer, retentionOutputs = nil, nil
+	})
+	retentionTimer = timer
+}
+
+// restoreRetention 恢复o创建时配置的保存时间和保留个数
+// 已经重新初始化时日志文件已按新的配置修改, 不再恢复
+func restoreRetention(o *outputs) {
+	if o != std().outputs {
+		return
+	}
+	o.setRetention(o.conf.MaxAge, o.conf.MaxBackups)
+}
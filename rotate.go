@@ -0,0 +1,81 @@
+package logs
+
+import (
+	"sync"
+	"time"
+)
+
+// rotator 按RotateInterval在每天或每小时开始时轮转日志文件
+// 轮转通过lumberjack的Rotate完成, 与写入互斥, 轮转时并发写入的日志不会丢失
+type rotator struct {
+	interval string
+	loc      *time.Location
+	// now 获取当前时间, 默认time.Now
+	now    func() time.Time
+	rotate func() error
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newRotator(interval string, loc *time.Location, rotate func() error) *rotator {
+	return &rotator{
+		interval: interval,
+		loc:      loc,
+		now:      time.Now,
+		rotate:   rotate,
+	}
+}
+
+// next 返回t之后的下一个轮转时间
+func (r *rotator) next(t time.Time) time.Time {
+	t = t.In(r.loc)
+	y, m, d := t.Date()
+	if r.interval == "hourly" {
+		return time.Date(y, m, d, t.Hour()+1, 0, 0, 0, r.loc)
+	}
+	return time.Date(y, m, d+1, 0, 0, 0, 0, r.loc)
+}
+
+// start 启动后台轮转, 已启动时不做任何事
+func (r *rotator) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run(r.stop, r.done)
+}
+
+// close 停止后台轮转并等待正在进行的轮转完成
+func (r *rotator) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop, r.done = nil, nil
+}
+
+func (r *rotator) run(stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		now := r.now()
+		timer := time.NewTimer(r.next(now).Sub(now))
+		select {
+		case <-timer.C:
+			_ = r.rotate()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package logs
+
+import (
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRotatorNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name     string
+		interval string
+		loc      *time.Location
+		now      time.Time
+		want     time.Time
+	}{
+		{"daily", "daily", time.UTC, time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"daily at midnight", "daily", time.UTC, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"daily end of month", "daily", time.UTC, time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"hourly", "hourly", time.UTC, time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC), time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)},
+		{"hourly end of day", "hourly", time.UTC, time.Date(2024, 5, 1, 23, 10, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		// 按时区计算, 上海的0点为UTC的16点
+		{"daily in zone", "daily", shanghai, time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRotator(tt.interval, tt.loc, nil)
+			if got := r.next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("next(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatorRun(t *testing.T) {
+	var rotations int32
+	r := newRotator("hourly", time.UTC, func() error {
+		atomic.AddInt32(&rotations, 1)
+		return nil
+	})
+	// 总是在整点之前10ms
+	r.now = func() time.Time { return time.Date(2024, 5, 1, 13, 59, 59, 990e6, time.UTC) }
+	r.start()
+	r.start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&rotations) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no rotation at the boundary")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r.close()
+	n := atomic.LoadInt32(&rotations)
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&rotations); got != n {
+		t.Errorf("rotations after close = %d, want %d", got, n)
+	}
+	r.close()
+}
+
+func TestRotateInterval(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{Level: "info", RotateInterval: "daily"})
+	o := std().outputs
+	if o.rotator == nil {
+		t.Fatal("no rotator with RotateInterval daily")
+	}
+	// 轮转同时作用于主文件和错误文件, 清理仍按MaxAge和MaxBackups
+	Info("day one")
+	Error("error day one")
+	if err := o.rotator.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	Info("day two")
+	_ = Sync()
+	for _, pattern := range []string{"test-*.log", "test_err-*.log"} {
+		if m, _ := filepath.Glob(filepath.Join(dir, pattern)); len(m) != 1 {
+			t.Errorf("%s matched %v, want one rotated file", pattern, m)
+		}
+	}
+	if got := readLog(t, filepath.Join(dir, "test.log")); !strings.Contains(got, "day two") || strings.Contains(got, "day one") {
+		t.Errorf("test.log = %q, want only the second day", got)
+	}
+}
@@ -0,0 +1,65 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// routeDebugEntries 开启RouteDebug后输出路由信息的日志条数
+const routeDebugEntries = 100
+
+// sink 一个日志输出及其级别规则的说明
+type sink struct {
+	name string
+	rule string
+	core zapcore.Core
+}
+
+func teeSinks(sinks []sink) zapcore.Core {
+	cores := make([]zapcore.Core, len(sinks))
+	for i, s := range sinks {
+		cores[i] = s.core
+	}
+	return zapcore.NewTee(cores...)
+}
+
+// routeDebugCore 将前routeDebugEntries条日志会写入和跳过的输出打印到stderr
+type routeDebugCore struct {
+	zapcore.Core
+	sinks     []sink
+	remaining *int64
+}
+
+func newRouteDebugCore(sinks []sink) zapcore.Core {
+	remaining := int64(routeDebugEntries)
+	return routeDebugCore{Core: teeSinks(sinks), sinks: sinks, remaining: &remaining}
+}
+
+func (c routeDebugCore) With(fields []zapcore.Field) zapcore.Core {
+	sinks := make([]sink, len(c.sinks))
+	for i, s := range c.sinks {
+		sinks[i] = sink{name: s.name, rule: s.rule, core: s.core.With(fields)}
+	}
+	return routeDebugCore{Core: teeSinks(sinks), sinks: sinks, remaining: c.remaining}
+}
+
+func (c routeDebugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if atomic.AddInt64(c.remaining, -1) >= 0 {
+		var matched, skipped []string
+		for _, s := range c.sinks {
+			desc := s.name + "[" + s.rule + "]"
+			if s.core.Enabled(ent.Level) {
+				matched = append(matched, desc)
+			} else {
+				skipped = append(skipped, desc)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "logs route: %s logger=%q msg=%q -> %s; skipped: %s\n",
+			strings.ToUpper(levelString(ent.Level)), ent.LoggerName, ent.Message,
+			strings.Join(matched, " "), strings.Join(skipped, " "))
+	}
+	return c.Core.Check(ent, ce)
+}
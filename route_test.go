@@ -0,0 +1,70 @@
+package logs
+
+import (
+	"strings"
+	"testing"
+)
+
+// routeLines 返回stderr中RouteDebug输出的路由信息
+func routeLines(out string) []string {
+	var lines []string
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "logs route: ") {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func TestRouteDebug(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{"info", func() { Info("route line") }, `logs route: INFO logger="" msg="route line" -> file[>=INFO] stdout[INFO..<ERROR]; skipped: error file[>=ERROR] stderr[>=ERROR]`},
+		{"error", func() { Error("route line") }, `logs route: ERROR logger="" msg="route line" -> file[>=INFO] error file[>=ERROR] stderr[>=ERROR]; skipped: stdout[INFO..<ERROR]`},
+		{"named", func() { Named("db").Warn("route line") }, `logs route: WARN logger="db" msg="route line" -> file[>=INFO] stdout[INFO..<ERROR]; skipped: error file[>=ERROR] stderr[>=ERROR]`},
+		{"With", func() { With("k", "v").Info("route line") }, `logs route: INFO logger="" msg="route line" -> file[>=INFO] stdout[INFO..<ERROR]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := initConsoleLogger(t, &LogConfig{Level: "info", RouteDebug: true})
+			tt.log()
+			Debug("filtered line")
+
+			var found bool
+			for _, l := range routeLines(stderr()) {
+				if strings.Contains(l, "filtered line") {
+					t.Errorf("route = %s, want no route for entries below the level", l)
+				}
+				found = found || strings.HasPrefix(l, tt.want)
+			}
+			if !found {
+				t.Errorf("stderr = %s, want %s", stderr(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteDebugLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		route bool
+		want  int
+	}{
+		{"enabled", true, routeDebugEntries},
+		{"disabled", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr := initConsoleLogger(t, &LogConfig{Level: "info", RouteDebug: tt.route})
+			for i := 0; i < routeDebugEntries+10; i++ {
+				Info("route line")
+			}
+			if got := len(routeLines(stderr())); got != tt.want {
+				t.Errorf("route lines = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
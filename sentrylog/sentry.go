@@ -0,0 +1,174 @@
+// Package sentrylog 将默认logger中error及以上级别的日志发送到Sentry
+package sentrylog
+
+import (
+	"errors"
+	"fmt"
+	"github.com/getsentry/sentry-go"
+	"github.com/xpfo-go/logs"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQueueSize    = 1000
+	defaultFlushTimeout = 2 * time.Second
+)
+
+var (
+	mu sync.Mutex
+	// sink 当前的Sentry输出, 未启用时为nil
+	sink *logs.AsyncCore
+)
+
+// Option Enable使用的配置项
+type Option func(*config)
+
+type config struct {
+	level        string
+	queueSize    int
+	flushTimeout time.Duration
+	client       sentry.ClientOptions
+}
+
+// Level 发送到Sentry的最低日志级别, 默认为error
+func Level(level string) Option {
+	return func(c *config) { c.level = level }
+}
+
+// QueueSize 等待发送的日志条数上限, 超过时丢弃新的日志, 默认1000
+func QueueSize(n int) Option {
+	return func(c *config) { c.queueSize = n }
+}
+
+// FlushTimeout Sync时等待发送完成的最长时间, 默认2秒
+func FlushTimeout(d time.Duration) Option {
+	return func(c *config) { c.flushTimeout = d }
+}
+
+// ClientOptions 修改创建Sentry客户端的配置, 如Environment Release HTTPTransport
+func ClientOptions(fn func(*sentry.ClientOptions)) Option {
+	return func(c *config) { fn(&c.client) }
+}
+
+// Enable 将默认logger中error及以上级别的日志发送到Sentry, 重复调用时替换之前的配置
+// 日志内容作为消息, 日志字段作为extra, 调用栈和调用位置作为extra中的stacktrace和caller, logger名称作为tag logger
+// 日志在后台发送, 队列已满时丢弃, Sentry不可用时不会阻塞日志输出
+// Sync、Drain和Close时等待发送完成或超时, 不再使用时调用Disable
+//
+//	err := sentrylog.Enable(dsn, sentrylog.Level("warn"))
+func Enable(dsn string, opts ...Option) error {
+	c := &config{
+		level:        "error",
+		queueSize:    defaultQueueSize,
+		flushTimeout: defaultFlushTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	lvl, err := logs.ParseLevel(c.level)
+	if err != nil {
+		return err
+	}
+	if c.queueSize <= 0 {
+		return fmt.Errorf("sentrylog: queue size must be positive, got %d", c.queueSize)
+	}
+	c.client.Dsn = dsn
+	client, err := sentry.NewClient(c.client)
+	if err != nil {
+		return fmt.Errorf("sentrylog: create sentry client: %w", err)
+	}
+
+	s, err := logs.AddAsyncCore(logs.AsyncCoreConfig{
+		Name:         "sentry",
+		Level:        lvl,
+		QueueSize:    c.queueSize,
+		FlushTimeout: c.flushTimeout,
+		Encode: func(ent zapcore.Entry, fields []zapcore.Field) (interface{}, error) {
+			return event(ent, fields), nil
+		},
+		Send: func(msg interface{}, done <-chan struct{}) error {
+			client.CaptureEvent(msg.(*sentry.Event), nil, nil)
+			return nil
+		},
+		Flush: func(timeout time.Duration) error {
+			if !client.Flush(timeout) {
+				return errors.New("sentrylog: sentry flush timed out")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	prev := sink
+	sink = s
+	mu.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// Disable 停止发送到Sentry, 等待队列中的日志发送完成或超时, 未启用时不做任何事
+func Disable() error {
+	mu.Lock()
+	prev := sink
+	sink = nil
+	mu.Unlock()
+	if prev == nil {
+		return nil
+	}
+	return prev.Close()
+}
+
+// Failures 当前的Sentry输出队列已满丢弃的日志条数
+func Failures() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	if sink == nil {
+		return 0
+	}
+	return sink.Failures()
+}
+
+// event 日志对应的Sentry事件
+func event(ent zapcore.Entry, fields []zapcore.Field) *sentry.Event {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	e := sentry.NewEvent()
+	e.Level = sentryLevel(ent.Level)
+	e.Message = ent.Message
+	e.Timestamp = ent.Time
+	e.Extra = enc.Fields
+	if ent.Caller.Defined {
+		e.Extra["caller"] = ent.Caller.String()
+	}
+	if ent.Stack != "" {
+		e.Extra["stacktrace"] = ent.Stack
+	}
+	if ent.LoggerName != "" {
+		e.Tags["logger"] = ent.LoggerName
+	}
+	return e
+}
+
+// sentryLevel 日志级别对应的Sentry级别
+func sentryLevel(lvl zapcore.Level) sentry.Level {
+	switch {
+	case lvl >= zapcore.PanicLevel:
+		return sentry.LevelFatal
+	case lvl >= zapcore.ErrorLevel:
+		return sentry.LevelError
+	case lvl == zapcore.WarnLevel:
+		return sentry.LevelWarning
+	case lvl == zapcore.InfoLevel:
+		return sentry.LevelInfo
+	}
+	return sentry.LevelDebug
+}
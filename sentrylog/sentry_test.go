@@ -0,0 +1,198 @@
+package sentrylog
+
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/xpfo-go/logs"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransport 记录发送的事件, 不发起网络请求
+type fakeTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (f *fakeTransport) Configure(sentry.ClientOptions) {}
+
+func (f *fakeTransport) SendEvent(e *sentry.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, e)
+}
+
+func (f *fakeTransport) Flush(time.Duration) bool {
+	return true
+}
+
+func (f *fakeTransport) sent() []*sentry.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*sentry.Event(nil), f.events...)
+}
+
+func initLogs(t *testing.T) {
+	t.Helper()
+	if err := logs.InitLogSetting(&logs.LogConfig{Dir: t.TempDir(), FileName: "test", DisableFile: true, DisableConsole: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Disable() })
+}
+
+func TestEnable(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		log       func()
+		wantLevel sentry.Level
+		wantMsg   string
+		wantExtra map[string]interface{}
+		wantTag   string
+	}{
+		{
+			name:      "error with fields",
+			log:       func() { logs.Named("billing").Errorw("charge failed", "order", 42) },
+			wantLevel: sentry.LevelError,
+			wantMsg:   "charge failed",
+			wantExtra: map[string]interface{}{"order": int64(42)},
+			wantTag:   "billing",
+		},
+		{
+			name:      "warn with lowered level",
+			opts:      []Option{Level("warn")},
+			log:       func() { logs.Warn("disk almost full") },
+			wantLevel: sentry.LevelWarning,
+			wantMsg:   "disk almost full",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initLogs(t)
+			transport := &fakeTransport{}
+			opts := append([]Option{ClientOptions(func(o *sentry.ClientOptions) { o.Transport = transport })}, tt.opts...)
+			if err := Enable("", opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			logs.Info("below level")
+			tt.log()
+			if err := logs.Sync(); err != nil {
+				t.Fatal(err)
+			}
+			events := transport.sent()
+			if len(events) != 1 {
+				t.Fatalf("events = %d, want 1", len(events))
+			}
+			e := events[0]
+			if e.Level != tt.wantLevel || e.Message != tt.wantMsg {
+				t.Errorf("event = %s %q, want %s %q", e.Level, e.Message, tt.wantLevel, tt.wantMsg)
+			}
+			for k, v := range tt.wantExtra {
+				if e.Extra[k] != v {
+					t.Errorf("extra %s = %#v, want %#v", k, e.Extra[k], v)
+				}
+			}
+			if _, ok := e.Extra["caller"]; !ok {
+				t.Errorf("extra = %v, want caller", e.Extra)
+			}
+			if e.Tags["logger"] != tt.wantTag {
+				t.Errorf("logger tag = %q, want %q", e.Tags["logger"], tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestSentryStacktraceExtra(t *testing.T) {
+	initLogs(t)
+	transport := &fakeTransport{}
+	if err := Enable("", ClientOptions(func(o *sentry.ClientOptions) { o.Transport = transport })); err != nil {
+		t.Fatal(err)
+	}
+	logs.Error("with stack")
+	_ = logs.Sync()
+	events := transport.sent()
+	if len(events) != 1 || !strings.Contains(events[0].Extra["stacktrace"].(string), "TestSentryStacktraceExtra") {
+		t.Errorf("events = %v, want a stacktrace extra", events)
+	}
+}
+
+func TestSentryHTTPTransport(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, r.URL.Path+" "+string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	initLogs(t)
+	dsn := strings.Replace(srv.URL, "http://", "http://key@", 1) + "/1"
+	if err := Enable(dsn); err != nil {
+		t.Fatal(err)
+	}
+	logs.Error("over http")
+	if err := logs.Drain(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "/api/1/") || !strings.Contains(bodies[0], "over http") {
+		t.Errorf("requests = %q, want one event posted to the project", bodies)
+	}
+}
+
+func TestSentryUnavailableDoesNotBlock(t *testing.T) {
+	initLogs(t)
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	dsn := strings.Replace(srv.URL, "http://", "http://key@", 1) + "/1"
+	if err := Enable(dsn, QueueSize(2), FlushTimeout(50*time.Millisecond), ClientOptions(func(o *sentry.ClientOptions) {
+		o.Transport = sentry.NewHTTPSyncTransport()
+	})); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		logs.Error("sentry is down")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("logging took %v while sentry is blocked", d)
+	}
+	if Failures() == 0 {
+		t.Error("Failures() = 0, want dropped entries counted")
+	}
+	if err := logs.Sync(); err == nil {
+		t.Error("Sync with a blocked sentry returned nil, want a timeout")
+	}
+}
+
+func TestEnableInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		opts []Option
+	}{
+		{"bad level", "", []Option{Level("loud")}},
+		{"bad queue size", "", []Option{QueueSize(0)}},
+		{"bad dsn", "not a dsn", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Enable(tt.dsn, tt.opts...); err == nil {
+				t.Error("Enable succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	slackQueueSize    = 100
+	slackFlushTimeout = 5 * time.Second
+	// slackAttempts 每条消息的最多发送次数, 只在429和5xx时重试
+	slackAttempts = 4
+	// slackMaxFields context块最多10个元素, 第一个为时间
+	slackMaxFields = 9
+	// slackBreakerFailures 连续失败该次数后熔断, 停止发送
+	slackBreakerFailures = 5
+	// slackMaxRetryAfter Retry-After等待时间的上限, 避免一条消息长时间阻塞队列
+	slackMaxRetryAfter = time.Minute
+)
+
+var (
+	slackMu sync.Mutex
+	// slackSink 当前的Slack输出, 未启用时为nil
+	slackSink *AsyncCore
+	// slackBackoff 第一次重试前的等待时间, 之后每次加倍
+	slackBackoff = time.Second
+	// slackBreakerCooldown 熔断后再次尝试发送前的等待时间
+	slackBreakerCooldown = 30 * time.Second
+)
+
+// EnableSlack 将默认logger中minLevel及以上级别的日志发送到Slack Incoming Webhook, 重复调用时替换之前的配置
+// 消息包含级别图标和日志内容, 时间和日志字段作为context块, channelOverride不为空时发送到该频道
+// 在后台goroutine发送, 429和5xx时按指数退避重试, 有Retry-After时按其等待, 最多等待1分钟
+// 连续失败多次后熔断, 期间的消息直接丢弃, 一段时间后用下一条消息探测是否恢复
+// 发送失败时不输出日志, 只计入SlackFailures, Sync和Drain时等待发送完成或超时, 不再使用时调用DisableSlack
+//
+//	err := logs.EnableSlack(webhook, "error", "#alerts")
+func EnableSlack(webhookURL string, minLevel string, channelOverride string) error {
+	lvl, err := parseLevel(minLevel)
+	if err != nil {
+		return fmt.Errorf("logs: invalid slack level %q, accepted values: %s", minLevel, levelNames)
+	}
+	if webhookURL == "" {
+		return errors.New("logs: slack webhook url is empty")
+	}
+
+	s := &slack{
+		webhook: webhookURL,
+		channel: channelOverride,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		now:     time.Now,
+	}
+	sink, err := AddAsyncCore(AsyncCoreConfig{
+		Name:         "slack",
+		Level:        lvl,
+		QueueSize:    slackQueueSize,
+		FlushTimeout: slackFlushTimeout,
+		Encode: func(ent zapcore.Entry, fields []zapcore.Field) (interface{}, error) {
+			return json.Marshal(s.message(ent, fields))
+		},
+		Send: func(msg interface{}, done <-chan struct{}) error {
+			return s.deliver(msg.([]byte), done)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	slackMu.Lock()
+	prev := slackSink
+	slackSink = sink
+	slackMu.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// DisableSlack 停止发送到Slack, 等待队列中的消息发送完成或超时, 未启用时不做任何事
+func DisableSlack() error {
+	slackMu.Lock()
+	prev := slackSink
+	slackSink = nil
+	slackMu.Unlock()
+	if prev == nil {
+		return nil
+	}
+	return prev.Close()
+}
+
+// SlackFailures 当前的Slack输出发送失败、熔断和队列已满丢弃的消息条数
+func SlackFailures() uint64 {
+	slackMu.Lock()
+	defer slackMu.Unlock()
+	if slackSink == nil {
+		return 0
+	}
+	return slackSink.Failures()
+}
+
+// slackBlock Slack消息中的一个块
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+// message 日志对应的Slack消息, section块为级别图标和日志内容, context块为时间、logger名称和日志字段
+func (s *slack) message(ent zapcore.Entry, fields []zapcore.Field) slackMessage {
+	level := strings.ToUpper(levelString(ent.Level))
+	elements := []*slackText{{Type: "mrkdwn", Text: "*time:* " + ent.Time.Format("2006-01-02 15:04:05.000")}}
+	if ent.LoggerName != "" {
+		elements = append(elements, &slackText{Type: "mrkdwn", Text: "*logger:* " + ent.LoggerName})
+	}
+	for _, f := range fields {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			if len(elements) <= slackMaxFields {
+				elements = append(elements, &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s:* %v", k, v)})
+			}
+		}
+	}
+	return slackMessage{
+		Channel: s.channel,
+		Text:    "[" + level + "] " + ent.Message,
+		Blocks: []slackBlock{
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: slackEmoji(ent.Level) + " *" + level + "* " + ent.Message}},
+			{Type: "context", Elements: elements},
+		},
+	}
+}
+
+// slackEmoji 日志级别对应的图标
+func slackEmoji(lvl zapcore.Level) string {
+	switch {
+	case lvl > zapcore.ErrorLevel:
+		return ":fire:"
+	case lvl == zapcore.ErrorLevel:
+		return ":red_circle:"
+	case lvl == zapcore.WarnLevel:
+		return ":warning:"
+	case lvl == zapcore.InfoLevel:
+		return ":information_source:"
+	}
+	return ":mag:"
+}
+
+// slack Slack消息的发送方, 只在AsyncCore的后台goroutine中调用, 熔断状态无需加锁
+type slack struct {
+	webhook string
+	channel string
+	client  *http.Client
+	// now 获取当前时间, 默认time.Now
+	now func() time.Time
+
+	// failures 连续失败的次数
+	failures int
+	// openUntil 熔断结束的时间, 之前的消息直接丢弃
+	openUntil time.Time
+}
+
+// deliver 按熔断状态发送一条消息, 熔断期间直接返回错误
+func (s *slack) deliver(body []byte, done <-chan struct{}) error {
+	if s.now().Before(s.openUntil) {
+		return errors.New("logs: slack circuit open")
+	}
+	if err := s.post(body, done); err != nil {
+		s.failures++
+		// 熔断结束后的第一条消息作为探测, 再次失败时重新熔断
+		if s.failures >= slackBreakerFailures {
+			s.openUntil = s.now().Add(slackBreakerCooldown)
+		}
+		return err
+	}
+	s.failures = 0
+	return nil
+}
+
+// post 发送一条消息, 429和5xx时重试
+func (s *slack) post(body []byte, done <-chan struct{}) error {
+	backoff := slackBackoff
+	for attempt := 1; ; attempt++ {
+		wait, retry, err := s.send(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == slackAttempts {
+			return err
+		}
+		if wait <= 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return err
+		}
+	}
+}
+
+// send 发送一次请求, retry表示是否可以重试, wait为Retry-After指定的等待时间
+func (s *slack) send(body []byte) (wait time.Duration, retry bool, err error) {
+	resp, err := s.client.Post(s.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return retryAfter(resp.Header.Get("Retry-After"), s.now()), true, fmt.Errorf("logs: slack status %d", resp.StatusCode)
+	}
+	return 0, false, fmt.Errorf("logs: slack status %d", resp.StatusCode)
+}
+
+// retryAfter 解析秒数或HTTP日期格式的Retry-After, 无法解析时返回0, 最多等待slackMaxRetryAfter
+func retryAfter(v string, now time.Time) time.Duration {
+	var wait time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		wait = t.Sub(now)
+	}
+	if wait < 0 {
+		return 0
+	}
+	if wait > slackMaxRetryAfter {
+		return slackMaxRetryAfter
+	}
+	return wait
+}
@@ -0,0 +1,156 @@
+package logs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackMessage(t *testing.T) {
+	initTestLogger(t, &LogConfig{DisableFile: true})
+	srv := newWebhookServer(t, "ok")
+	if err := EnableSlack(srv.URL, "warn", "#alerts"); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableSlack()
+
+	Info("not sent")
+	Named("db").Errorw("query failed", "table", "orders")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.requests()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %q, want 1", reqs)
+	}
+	var msg slackMessage
+	if err := json.Unmarshal([]byte(reqs[0]), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Channel != "#alerts" || msg.Text != "[ERROR] query failed" {
+		t.Errorf("message = %+v", msg)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"section", msg.Blocks[0].Text.Text, ":red_circle: *ERROR* query failed"},
+		{"logger", msg.Blocks[1].Elements[1].Text, "*logger:* db"},
+		{"field", reqs[0], `*table:* orders`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.got, tt.want) {
+				t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlackRetry(t *testing.T) {
+	fastRetries(t)
+	tests := []struct {
+		name         string
+		status       []int
+		wantRequests int
+		wantFailures uint64
+	}{
+		{"429 then ok", []int{http.StatusTooManyRequests, http.StatusOK}, 2, 0},
+		{"5xx exhausted", []int{500, 502, 503, 504}, slackAttempts, 1},
+		{"4xx not retried", []int{http.StatusBadRequest}, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{DisableFile: true})
+			srv := newWebhookServer(t, "", tt.status...)
+			if err := EnableSlack(srv.URL, "error", ""); err != nil {
+				t.Fatal(err)
+			}
+			defer DisableSlack()
+
+			Error("retry me")
+			_ = Sync()
+			if got := len(srv.requests()); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if got := SlackFailures(); got != tt.wantFailures {
+				t.Errorf("SlackFailures() = %d, want %d", got, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"3600", slackMaxRetryAfter},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0},
+		{now.Add(time.Hour).Format(http.TimeFormat), slackMaxRetryAfter},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSlackBreaker(t *testing.T) {
+	fastRetries(t)
+	initTestLogger(t, &LogConfig{DisableFile: true})
+	// 每条消息只发送一次, 连续失败后熔断
+	status := make([]int, slackBreakerFailures)
+	for i := range status {
+		status[i] = http.StatusBadRequest
+	}
+	srv := newWebhookServer(t, "", status...)
+	if err := EnableSlack(srv.URL, "error", ""); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableSlack()
+
+	for i := 0; i < slackBreakerFailures+3; i++ {
+		Error("failing")
+	}
+	_ = Sync()
+	if got := len(srv.requests()); got != slackBreakerFailures {
+		t.Errorf("requests while open = %d, want %d", got, slackBreakerFailures)
+	}
+	if got := SlackFailures(); got != slackBreakerFailures+3 {
+		t.Errorf("SlackFailures() = %d, want %d", got, slackBreakerFailures+3)
+	}
+
+	// 熔断结束后用下一条消息探测
+	time.Sleep(2 * slackBreakerCooldown)
+	Error("probe")
+	_ = Sync()
+	if got := len(srv.requests()); got != slackBreakerFailures+1 {
+		t.Errorf("requests after cooldown = %d, want %d", got, slackBreakerFailures+1)
+	}
+}
+
+func TestEnableSlackInvalid(t *testing.T) {
+	tests := []struct {
+		name, url, level string
+	}{
+		{"empty url", "", "error"},
+		{"bad level", "http://example.com", "loud"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := EnableSlack(tt.url, tt.level, ""); err == nil {
+				t.Error("EnableSlack succeeded, want error")
+			}
+		})
+	}
+}
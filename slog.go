@@ -0,0 +1,185 @@
+//go:build go1.21
+
+package logs
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"runtime"
+)
+
+// NewSlogHandler 获取写入默认logger的slog.Handler, 日志使用相同的输出和日志级别, 重新初始化后依然有效
+// opts为nil时使用默认值, AddSource为true时输出调用位置, Level为额外的最低级别, ReplaceAttr对日志字段生效
+// slog的级别低于debug时使用trace级别, 分组在json格式中输出为嵌套的对象
+//
+//	logger := slog.New(logs.NewSlogHandler(nil))
+func NewSlogHandler(opts *slog.HandlerOptions) slog.Handler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &slogHandler{opts: *opts}
+}
+
+type slogHandler struct {
+	opts slog.HandlerOptions
+	// fields WithAttrs添加的字段, WithGroup添加为zap.Namespace
+	fields []zap.Field
+	// groups 当前所在的分组, 用于ReplaceAttr
+	groups []string
+	// pending 还没有字段的分组, 添加字段时才写入zap.Namespace, 空的分组不输出
+	pending []string
+}
+
+// slogLevel 将slog的级别转为zap的级别
+func slogLevel(lvl slog.Level) zapcore.Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return traceLevel
+	case lvl < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case lvl < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case lvl < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+func (h *slogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	if h.opts.Level != nil && lvl < h.opts.Level.Level() {
+		return false
+	}
+	return lz().Core().Enabled(slogLevel(lvl))
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   slogLevel(r.Level),
+		Time:    r.Time,
+		Message: r.Message,
+	}
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ent.Caller.Function = frame.Function
+	}
+	ce := lz().Core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	var attrs []zap.Field
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = h.appendAttr(attrs, a, h.groups)
+		return true
+	})
+	if len(attrs) == 0 {
+		ce.Write(h.fields...)
+		return nil
+	}
+	ce.Write(append(h.openGroups(), attrs...)...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zap.Field
+	for _, a := range attrs {
+		fields = h.appendAttr(fields, a, h.groups)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	c := h.clone()
+	c.fields = append(h.openGroups(), fields...)
+	c.pending = nil
+	return c
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := h.clone()
+	c.groups = append(c.groups, name)
+	c.pending = append(c.pending, name)
+	return c
+}
+
+func (h *slogHandler) clone() *slogHandler {
+	return &slogHandler{
+		opts:    h.opts,
+		fields:  append([]zap.Field(nil), h.fields...),
+		groups:  append([]string(nil), h.groups...),
+		pending: append([]string(nil), h.pending...),
+	}
+}
+
+// openGroups 返回h.fields的副本, 并为还没有字段的分组添加zap.Namespace
+func (h *slogHandler) openGroups() []zap.Field {
+	fields := make([]zap.Field, 0, len(h.fields)+len(h.pending))
+	fields = append(fields, h.fields...)
+	for _, name := range h.pending {
+		fields = append(fields, zap.Namespace(name))
+	}
+	return fields
+}
+
+// appendAttr 将a转为字段添加到fields, 空的属性和分组不输出, 键为空的分组展开到当前层级
+func (h *slogHandler) appendAttr(fields []zap.Field, a slog.Attr, groups []string) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = h.appendAttr(fields, ga, groups)
+			}
+			return fields
+		}
+		inner := append(groups[:len(groups):len(groups)], a.Key)
+		var groupFields []zap.Field
+		for _, ga := range attrs {
+			groupFields = h.appendAttr(groupFields, ga, inner)
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(groupFields)))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, v.Time()))
+	}
+	return append(fields, zap.Any(a.Key, v.Any()))
+}
+
+// slogGroup slog分组转换后的字段, 输出为对象
+type slogGroup []zap.Field
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range g {
+		f.AddTo(enc)
+	}
+	return nil
+}
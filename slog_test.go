@@ -0,0 +1,165 @@
+//go:build go1.21
+
+package logs
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// slogUser 实现slog.LogValuer, 输出为分组
+type slogUser struct{ id int }
+
+func (u slogUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.id))
+}
+
+// slogLine 返回日志文件中包含msg的一行
+func slogLine(t *testing.T, dir, msg string) string {
+	t.Helper()
+	_ = Sync()
+	for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+		if strings.Contains(l, msg) {
+			return l
+		}
+	}
+	return ""
+}
+
+func TestSlogHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *slog.HandlerOptions
+		log    func(l *slog.Logger)
+		want   []string
+		absent []string
+	}{
+		{"attrs", nil, func(l *slog.Logger) { l.Info("slog line", "k", "v", "n", 1) }, []string{`"level":"INFO"`, `"k":"v","n":1`}, nil},
+		{"kinds", nil, func(l *slog.Logger) {
+			l.Info("slog line", slog.Bool("b", true), slog.Float64("f", 1.5), slog.Uint64("u", 2), slog.Any("s", []int{1}))
+		}, []string{`"b":true,"f":1.5,"u":2,"s":[1]`}, nil},
+		{"group attr", nil, func(l *slog.Logger) { l.Info("slog line", slog.Group("g", "a", 1, slog.Group("h", "b", 2))) }, []string{`"g":{"a":1,"h":{"b":2}}`}, nil},
+		{"empty group omitted", nil, func(l *slog.Logger) { l.Info("slog line", slog.Group("g"), "k", "v") }, []string{`"k":"v"`}, []string{`"g"`}},
+		{"empty key group inlined", nil, func(l *slog.Logger) { l.Info("slog line", slog.Group("", "a", 1)) }, []string{`"a":1`}, nil},
+		{"WithGroup", nil, func(l *slog.Logger) { l.WithGroup("req").Info("slog line", "id", 1) }, []string{`"req":{"id":1}`}, nil},
+		{"nested WithGroup and With", nil, func(l *slog.Logger) {
+			l.WithGroup("a").With("x", 1).WithGroup("b").Info("slog line", "y", 2)
+		}, []string{`"a":{"x":1,"b":{"y":2}}`}, nil},
+		{"empty WithGroup omitted", nil, func(l *slog.Logger) { l.With("k", "v").WithGroup("g").Info("slog line") }, []string{`"k":"v"`}, []string{`"g"`}},
+		{"empty nested WithGroup omitted", nil, func(l *slog.Logger) { l.WithGroup("a").With("x", 1).WithGroup("b").Info("slog line") }, []string{`"a":{"x":1}}`}, []string{`"b"`}},
+		{"LogValuer", nil, func(l *slog.Logger) { l.Info("slog line", "user", slogUser{7}) }, []string{`"user":{"id":7}`}, nil},
+		{"ReplaceAttr", &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "secret" {
+				return slog.Attr{}
+			}
+			if len(groups) > 0 && a.Key == "id" {
+				a.Key = strings.Join(groups, ".") + ".id"
+			}
+			return a
+		}}, func(l *slog.Logger) {
+			l.WithGroup("req").Info("slog line", "secret", "x", slog.Group("user", "id", 1))
+		}, []string{`"req":{"user":{"req.user.id":1}}`}, []string{"secret"}},
+		{"AddSource", &slog.HandlerOptions{AddSource: true}, func(l *slog.Logger) { l.Info("slog line") }, []string{`"caller":"module/slog_test.go:`}, nil},
+		{"no source", nil, func(l *slog.Logger) { l.Info("slog line") }, nil, []string{`"caller"`}},
+		{"trace", nil, func(l *slog.Logger) { l.Log(nil, slog.LevelDebug-4, "slog line") }, []string{`"level":"TRACE"`}, nil},
+		{"warn", nil, func(l *slog.Logger) { l.Warn("slog line") }, []string{`"level":"WARN"`}, nil},
+		{"error", nil, func(l *slog.Logger) { l.Log(nil, slog.LevelError+4, "slog line") }, []string{`"level":"ERROR"`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "trace", Encoding: "json", StacktraceLevel: "disabled"})
+			tt.log(slog.New(NewSlogHandler(tt.opts)))
+
+			line := slogLine(t, dir, "slog line")
+			if line == "" {
+				t.Fatal("no entry written")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("line = %s, want %s", line, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(line, absent) {
+					t.Errorf("line = %s, want no %s", line, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		level string
+		opts  *slog.HandlerOptions
+		lvl   slog.Level
+		want  bool
+	}{
+		{"info at info", "info", nil, slog.LevelInfo, true},
+		{"debug at info", "info", nil, slog.LevelDebug, false},
+		{"trace at trace", "trace", nil, slog.LevelDebug - 4, true},
+		{"trace at debug", "debug", nil, slog.LevelDebug - 4, false},
+		{"handler level", "debug", &slog.HandlerOptions{Level: slog.LevelWarn}, slog.LevelInfo, false},
+		{"handler level passed", "debug", &slog.HandlerOptions{Level: slog.LevelWarn}, slog.LevelError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestLogger(t, &LogConfig{Level: tt.level})
+			if got := NewSlogHandler(tt.opts).Enabled(nil, tt.lvl); got != tt.want {
+				t.Errorf("Enabled(%s) = %v, want %v", tt.lvl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerFollowsLevel(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{Level: "warn", Encoding: "json"})
+	l := slog.New(NewSlogHandler(nil))
+	l.Info("before SetLevel")
+	if err := SetLevel("info"); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after SetLevel")
+
+	if line := slogLine(t, dir, "before SetLevel"); line != "" {
+		t.Errorf("line = %s, want it filtered", line)
+	}
+	if line := slogLine(t, dir, "after SetLevel"); line == "" {
+		t.Error("entry after SetLevel not written")
+	}
+}
+
+func TestSlogHandlerConcurrent(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+	base := slog.New(NewSlogHandler(nil)).WithGroup("g").With("shared", 1)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			base.With("i", i).WithGroup("inner").Info("concurrent slog", "j", i)
+		}(i)
+	}
+	wg.Wait()
+	_ = Sync()
+
+	var lines int
+	for _, l := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+		if !strings.Contains(l, "concurrent slog") {
+			continue
+		}
+		lines++
+		if !strings.Contains(l, `"g":{"shared":1,"i":`) || !strings.Contains(l, `"inner":{"j":`) {
+			t.Errorf("line = %s, want the shared and own fields", l)
+		}
+	}
+	if lines != n {
+		t.Errorf("lines = %d, want %d", lines, n)
+	}
+}
@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sync/atomic"
+)
+
+// Restorer 通过Snapshot保存的日志状态
+type Restorer struct {
+	logger      *zap.SugaredLogger
+	std         *Logger
+	level       zapcore.Level
+	conf        *LogConfig
+	namedLevels map[string]zapcore.Level
+}
+
+// Snapshot 保存当前的logger、日志级别、日志配置和按名称设置的日志级别, 不持有输出, 之后重新初始化时照常关闭
+//
+//	defer logs.Snapshot().Restore()
+func Snapshot() Restorer {
+	namedLevelsMu.RLock()
+	levels := make(map[string]zapcore.Level, len(namedLevels))
+	for name, lvl := range namedLevels {
+		levels[name] = lvl
+	}
+	namedLevelsMu.RUnlock()
+
+	return Restorer{
+		logger:      l(),
+		std:         std(),
+		level:       atomicLevel.Level(),
+		conf:        getConf(),
+		namedLevels: levels,
+	}
+}
+
+// Restore 恢复到Snapshot时的日志状态, 默认Logger已被替换时按保存的配置重新创建输出, 并关闭当前的输出
+func (r Restorer) Restore() {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if cur := std(); cur != r.std {
+		c := r.conf
+		if r.std.implicit {
+			c = copyConfig(r.conf)
+			c.LazyFile = true
+		}
+		lg, err := newLogger(c, atomicLevel, addedCoresCore{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logs: restore snapshot: %v\n", err)
+			return
+		}
+		lg.implicit = r.std.implicit
+		setStd(lg)
+		_ = cur.stop()
+		lg.start()
+	}
+	// SetLogger设置的外部logger不属于默认Logger, 直接恢复
+	if r.conf.ExternalLogger {
+		setL(r.logger)
+	} else {
+		setL(std().l)
+	}
+	atomicLevel.SetLevel(r.level)
+	logConf.Store(r.conf)
+
+	namedLevelsMu.Lock()
+	namedLevels = make(map[string]zapcore.Level, len(r.namedLevels))
+	for name, lvl := range r.namedLevels {
+		namedLevels[name] = lvl
+	}
+	atomic.AddUint64(&namedLevelsGen, 1)
+	namedLevelsMu.Unlock()
+}
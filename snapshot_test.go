@@ -0,0 +1,52 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T)
+	}{
+		{"reinit", func(t *testing.T) {
+			if err := InitLogSetting(&LogConfig{Dir: t.TempDir(), FileName: "other", Level: "error", DisableConsole: true}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"reinit twice", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				if err := InitLogSetting(&LogConfig{Dir: t.TempDir(), FileName: "other", DisableConsole: true}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}},
+		{"set level", func(t *testing.T) { SetLevel("error") }},
+		{"set logger", func(t *testing.T) { SetLogger(zap.NewNop()) }},
+		{"module level", func(t *testing.T) { _ = SetModuleLevel("db", "error") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{MainBuffered: true})
+			r := Snapshot()
+			tt.change(t)
+			r.Restore()
+
+			if got := GetLogConf(); got.FileName != "test" || got.Dir != dir || got.ExternalLogger {
+				t.Errorf("GetLogConf() after Restore = %q %q external %v, want test %q", got.FileName, got.Dir, got.ExternalLogger, dir)
+			}
+			Info("restored info")
+			Named("db").Info("restored module")
+			_ = Sync()
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			for _, want := range []string{"restored info", "restored module"} {
+				if !strings.Contains(got, want) {
+					t.Errorf("log file after Restore = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log"
+)
+
+// stdLogDepth stdLogWriter.Write到调用标准库log的位置之间的调用层数: log.(*Logger).output, log.Print
+const stdLogDepth = 3
+
+// RedirectStdLog 将标准库log的输出以info级别写入默认logger, 与RedirectStdLogAt("info")相同
+func RedirectStdLog() (restore func(), err error) {
+	return RedirectStdLogAt("info")
+}
+
+// RedirectStdLogAt 将标准库log的输出以level级别写入默认logger, 重新初始化后依然有效
+// 返回的函数恢复标准库log之前的输出、前缀和flag
+//
+//	restore, err := logs.RedirectStdLogAt("warn")
+//	defer restore()
+func RedirectStdLogAt(level string) (restore func(), err error) {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("logs: invalid level %q, accepted values: %s", level, levelNames)
+	}
+	// zap.RedirectStdLogAt恢复时总是输出到os.Stderr, 且不支持trace级别
+	flags, prefix, out := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(newStdLogWriter(lvl))
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(out)
+	}, nil
+}
+
+// NewStdLogAt 获取以level级别写入默认logger的*log.Logger, 重新初始化后依然有效
+//
+//	errorLog, err := logs.NewStdLogAt("error")
+//	server := &http.Server{ErrorLog: errorLog}
+func NewStdLogAt(level string) (*log.Logger, error) {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("logs: invalid level %q, accepted values: %s", level, levelNames)
+	}
+	return log.New(newStdLogWriter(lvl), "", 0), nil
+}
+
+// stdLogWriter 将标准库log的每次输出作为一条lvl级别的日志写入默认logger, 调用位置为调用标准库log的位置
+type stdLogWriter struct {
+	l   *zap.Logger
+	lvl zapcore.Level
+}
+
+func newStdLogWriter(lvl zapcore.Level) stdLogWriter {
+	return stdLogWriter{l: Desugar().WithOptions(zap.AddCallerSkip(stdLogDepth)), lvl: lvl}
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.l.Log(w.lvl, string(bytes.TrimSuffix(p, []byte("\n"))))
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"bytes"
+	"go.uber.org/zap/zapcore"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect func() (func(), error)
+		want     zapcore.Level
+		wantErr  bool
+	}{
+		{"default info", RedirectStdLog, zapcore.InfoLevel, false},
+		{"warn", func() (func(), error) { return RedirectStdLogAt("warn") }, zapcore.WarnLevel, false},
+		{"invalid level", func() (func(), error) { return RedirectStdLogAt("loud") }, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			var prev bytes.Buffer
+			log.SetOutput(&prev)
+			log.SetPrefix("prev: ")
+			defer func() {
+				log.SetOutput(os.Stderr)
+				log.SetPrefix("")
+			}()
+
+			restore, err := tt.redirect()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			log.Print("stdlib line")
+			entries := recorded.FilterMessage("stdlib line").All()
+			if len(entries) != 1 || entries[0].Level != tt.want || !strings.HasSuffix(entries[0].Caller.File, "stdlog_test.go") {
+				t.Fatalf("entries = %+v, want one %s entry with the log.Print caller", entries, tt.want)
+			}
+
+			// 恢复之前的输出和前缀
+			restore()
+			log.Print("after restore")
+			if got := prev.String(); !strings.Contains(got, "prev: ") || !strings.Contains(got, "after restore") || strings.Contains(got, "stdlib line") {
+				t.Errorf("previous writer = %q, want only the entry after restore", got)
+			}
+		})
+	}
+}
+
+func TestNewStdLogAt(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    zapcore.Level
+		wantErr bool
+	}{
+		{"error", zapcore.ErrorLevel, false},
+		{"trace", TraceLevel, false},
+		{"loud", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			recorded := observeDefault(t, "trace")
+			sl, err := NewStdLogAt(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			sl.Print("leveled line")
+			entries := recorded.FilterMessage("leveled line").All()
+			if len(entries) != 1 || entries[0].Level != tt.want {
+				t.Errorf("entries = %+v, want one %s entry", entries, tt.want)
+			}
+		})
+	}
+}
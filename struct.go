@@ -0,0 +1,135 @@
+package logs
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	redactedValue = "******"
+	// maxStructDepth 嵌套层数限制, 避免循环引用导致无限递归
+	maxStructDepth = 10
+)
+
+// LogStruct 以Info级别输出结构体, 结构体的每个导出字段作为一个日志字段
+// 字段标签 log:"-" 不输出该字段, log:"redact" 输出时隐藏字段值, 嵌套的结构体同样生效
+//
+//	type User struct {
+//		Name     string
+//		Password string `log:"redact"`
+//		Token    string `log:"-"`
+//	}
+func LogStruct(msg string, v interface{}) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		l().Infow(msg, "value", v)
+		return
+	}
+
+	var kv []interface{}
+	structFields(rv, 0, func(name string, val interface{}) {
+		kv = append(kv, name, val)
+	})
+	l().Infow(msg, kv...)
+}
+
+// structFields 按声明顺序遍历结构体的导出字段, 处理log标签
+func structFields(rv reflect.Value, depth int, fn func(name string, val interface{})) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		switch strings.TrimSpace(f.Tag.Get("log")) {
+		case "-":
+			continue
+		case "redact":
+			fn(f.Name, redactedValue)
+		default:
+			fn(f.Name, structValue(rv.Field(i), depth+1))
+		}
+	}
+}
+
+// structValue 将值中的结构体转换为map, 以便嵌套结构体的log标签生效
+func structValue(rv reflect.Value, depth int) interface{} {
+	if depth > maxStructDepth {
+		return "..."
+	}
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return structValue(rv.Elem(), depth)
+	case reflect.Struct:
+		if !hasExportedField(rv.Type()) {
+			return rv.Interface()
+		}
+		m := make(map[string]interface{}, rv.NumField())
+		structFields(rv, depth, func(name string, val interface{}) {
+			m[name] = val
+		})
+		return m
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		if !mayContainStruct(rv.Type().Elem()) {
+			return rv.Interface()
+		}
+		s := make([]interface{}, rv.Len())
+		for i := range s {
+			s[i] = structValue(rv.Index(i), depth+1)
+		}
+		return s
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil
+		}
+		if !mayContainStruct(rv.Type().Elem()) {
+			return rv.Interface()
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[toString(iter.Key())] = structValue(iter.Value(), depth+1)
+		}
+		return m
+	}
+	if rv.CanInterface() {
+		return rv.Interface()
+	}
+	return nil
+}
+
+// mayContainStruct 判断该类型的值是否可能包含需要处理log标签的结构体
+func mayContainStruct(rt reflect.Type) bool {
+	for rt.Kind() == reflect.Ptr || rt.Kind() == reflect.Slice || rt.Kind() == reflect.Array || rt.Kind() == reflect.Map {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Interface || rt.Kind() == reflect.Struct && hasExportedField(rt)
+}
+
+func hasExportedField(rt reflect.Type) bool {
+	for i := 0; i < rt.NumField(); i++ {
+		if rt.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func toString(rv reflect.Value) string {
+	if rv.Kind() == reflect.String {
+		return rv.String()
+	}
+	if s, ok := contextValueString(rv.Interface()); ok {
+		return s
+	}
+	return rv.Type().String()
+}
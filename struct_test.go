@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type structTestAddress struct {
+	City   string
+	Street string `log:"redact"`
+}
+
+type structTestUser struct {
+	Name     string
+	Password string `log:"redact"`
+	Token    string `log:"-"`
+	age      int
+	Address  structTestAddress
+	Home     *structTestAddress
+	Previous []structTestAddress
+	Tags     map[string]structTestAddress
+}
+
+type structTestNode struct {
+	Name string
+	Next *structTestNode
+}
+
+func TestLogStruct(t *testing.T) {
+	cyclic := &structTestNode{Name: "loop"}
+	cyclic.Next = cyclic
+
+	tests := []struct {
+		name   string
+		v      interface{}
+		want   []string
+		absent []string
+	}{
+		{"redacted", structTestUser{Name: "bob", Password: "hunter2"}, []string{`"Name":"bob","Password":"******"`}, []string{"hunter2"}},
+		{"omitted", structTestUser{Token: "t-1"}, nil, []string{`"Token"`, "t-1"}},
+		{"unexported", structTestUser{age: 30}, nil, []string{`"age"`}},
+		{"nested", structTestUser{Address: structTestAddress{City: "sh", Street: "secret st"}}, []string{`"Address":{"City":"sh","Street":"******"}`}, []string{"secret st"}},
+		{"nested pointer", structTestUser{Home: &structTestAddress{City: "bj", Street: "secret st"}}, []string{`"Home":{"City":"bj","Street":"******"}`}, []string{"secret st"}},
+		{"nil nested pointer", structTestUser{}, []string{`"Home":null`}, nil},
+		{"slice", structTestUser{Previous: []structTestAddress{{City: "gz", Street: "secret st"}}}, []string{`"Previous":[{"City":"gz","Street":"******"}]`}, []string{"secret st"}},
+		{"map", structTestUser{Tags: map[string]structTestAddress{"work": {City: "sz", Street: "secret st"}}}, []string{`"Tags":{"work":{"City":"sz","Street":"******"}}`}, []string{"secret st"}},
+		{"pointer", &structTestUser{Name: "bob", Password: "hunter2"}, []string{`"Name":"bob","Password":"******"`}, []string{"hunter2"}},
+		{"nil pointer", (*structTestUser)(nil), []string{`"value":null`}, []string{`"Name"`}},
+		{"nil", nil, []string{`"value":null`}, nil},
+		{"not a struct", 42, []string{`"value":42`}, nil},
+		{"cycle", cyclic, []string{`"Name":"loop","Next":{"Name":"loop"`, `"..."`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+			LogStruct("struct line", tt.v)
+			_ = Sync()
+
+			got := readLog(t, filepath.Join(dir, "test.log"))
+			if !strings.Contains(got, `"msg":"struct line"`) {
+				t.Fatalf("log = %s, want the struct line", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("log = %s, want %s", got, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("log = %s, want no %s", got, absent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+	syslogFlushTimeout = 5 * time.Second
+	// syslogQueueSize 等待发送的消息条数上限, 超过时丢弃新的消息
+	syslogQueueSize = 1000
+	// syslogMaxBackoff 连续连接失败时重连等待时间的上限
+	syslogMaxBackoff = 30 * time.Second
+	// syslogFacility 使用user-level facility
+	syslogFacility = 1
+	// syslogSDID 结构化数据的SD-ID, 32473为RFC5424保留的示例企业号
+	syslogSDID = "fields@32473"
+)
+
+// syslogBackoff 第一次重连前的等待时间, 之后每次加倍
+var syslogBackoff = 100 * time.Millisecond
+
+// syslogWriter 发送syslog消息到远程服务器, 写入只放入有界队列, 由后台goroutine发送
+// 连接失败时按指数退避重连, 期间队列已满时丢弃新的消息并计数
+type syslogWriter struct {
+	// dropped 队列已满和发送失败丢弃的消息条数, 放在首位保证32位平台上的原子操作对齐
+	dropped uint64
+	network string
+	addr    string
+	items   chan syslogItem
+	done    chan struct{}
+
+	startOnce sync.Once
+	closeOnce sync.Once
+	// stopped 后台goroutine退出后关闭
+	stopped chan struct{}
+}
+
+// syslogItem 队列中的消息, flushed不为nil时为Sync的标记, 处理到此处时关闭
+type syslogItem struct {
+	msg     []byte
+	flushed chan struct{}
+}
+
+func newSyslogWriter(remote string) (*syslogWriter, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "tcp" && u.Scheme != "udp" {
+		return nil, fmt.Errorf("unsupported syslog network %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing syslog host in %q", remote)
+	}
+	return &syslogWriter{
+		network: u.Scheme,
+		addr:    u.Host,
+		items:   make(chan syslogItem, syslogQueueSize),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}, nil
+}
+
+// Write 将消息放入发送队列, 不会阻塞, 队列已满时丢弃并计入dropped
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	// TCP使用RFC6587的octet-counting分帧
+	var msg []byte
+	if w.network == "tcp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(p))), p...)
+	} else {
+		msg = append([]byte(nil), p...)
+	}
+	select {
+	case w.items <- syslogItem{msg: msg}:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// start 启动后台发送, 重复调用时不做任何事
+func (w *syslogWriter) start() {
+	w.startOnce.Do(func() {
+		go w.run()
+	})
+}
+
+func (w *syslogWriter) run() {
+	defer close(w.stopped)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	backoff := syslogBackoff
+	// send 发送一条消息, 未连接或写入失败时重连重试一次
+	send := func(msg []byte) bool {
+		for i := 0; i < 2; i++ {
+			if conn == nil {
+				c, err := net.DialTimeout(w.network, w.addr, syslogDialTimeout)
+				if err != nil {
+					return false
+				}
+				conn = c
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+			if _, err := conn.Write(msg); err == nil {
+				return true
+			}
+			_ = conn.Close()
+			conn = nil
+		}
+		return false
+	}
+	for {
+		select {
+		case item := <-w.items:
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			if send(item.msg) {
+				backoff = syslogBackoff
+				continue
+			}
+			atomic.AddUint64(&w.dropped, 1)
+			// 连接失败后等待一段时间再重连, 期间的消息留在队列中
+			select {
+			case <-time.After(backoff):
+			case <-w.done:
+				return
+			}
+			if backoff *= 2; backoff > syslogMaxBackoff {
+				backoff = syslogMaxBackoff
+			}
+		case <-w.done:
+			// 已连接时发送队列中剩余的消息, 不再重连
+			for conn != nil {
+				select {
+				case item := <-w.items:
+					if item.flushed != nil {
+						close(item.flushed)
+					} else if !send(item.msg) {
+						atomic.AddUint64(&w.dropped, 1)
+					}
+				default:
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+// sync 等待队列中的消息处理完成, 超时返回错误, 返回并清零丢弃的消息条数
+func (w *syslogWriter) sync() error {
+	timer := time.NewTimer(syslogFlushTimeout)
+	defer timer.Stop()
+
+	flushed := make(chan struct{})
+	select {
+	case w.items <- syslogItem{flushed: flushed}:
+	case <-timer.C:
+		return errors.New("logs: syslog flush timed out")
+	case <-w.stopped:
+		return nil
+	}
+	select {
+	case <-flushed:
+	case <-timer.C:
+		return errors.New("logs: syslog flush timed out")
+	case <-w.stopped:
+		return nil
+	}
+	if n := atomic.SwapUint64(&w.dropped, 0); n > 0 {
+		return fmt.Errorf("logs: syslog dropped %d messages", n)
+	}
+	return nil
+}
+
+// Close 停止后台发送并关闭连接, 已连接时先发送队列中剩余的消息
+func (w *syslogWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	w.startOnce.Do(func() {
+		close(w.stopped)
+	})
+	<-w.stopped
+	return nil
+}
+
+// syslogCore 将日志按RFC5424格式发送到远程syslog, 字段作为结构化数据输出
+type syslogCore struct {
+	zapcore.LevelEnabler
+	w       *syslogWriter
+	appName string
+	fields  []zapcore.Field
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = append(append([]zapcore.Field(nil), c.fields...), fields...)
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	msgID := "-"
+	if ent.LoggerName != "" {
+		msgID = syslogHeaderValue(ent.LoggerName, 32)
+	}
+	msg := ent.Message
+	if ent.Stack != "" {
+		msg += "\n" + ent.Stack
+	}
+	line := fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		syslogFacility*8+syslogSeverity(ent.Level),
+		ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderValue(hostname(), 255),
+		syslogHeaderValue(c.appName, 48),
+		os.Getpid(),
+		msgID,
+		syslogStructuredData(enc.Fields),
+		msg,
+	)
+	_, err := c.w.Write([]byte(line))
+	return err
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
+
+func syslogSeverity(lvl zapcore.Level) int {
+	switch lvl {
+	case traceLevel, zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	case zapcore.FatalLevel:
+		return 0
+	}
+	return 5
+}
+
+func syslogStructuredData(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("[" + syslogSDID)
+	for _, k := range keys {
+		sb.WriteString(" " + syslogParamName(k) + `="`)
+		sb.WriteString(syslogParamEscaper.Replace(fmt.Sprint(fields[k])))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamName PARAM-NAME最长32个字符, 不能包含'=', ' ', ']', '"'
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogHeaderValue 头部字段只允许可打印ASCII字符, 且有最大长度限制
+func syslogHeaderValue(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, v)
+	if v == "" {
+		return "-"
+	}
+	if len(v) > max {
+		v = v[:max]
+	}
+	return v
+}
+
+var (
+	hostnameOnce sync.Once
+	hostnameVal  string
+)
+
+func hostname() string {
+	hostnameOnce.Do(func() {
+		hostnameVal, _ = os.Hostname()
+	})
+	return hostnameVal
+}
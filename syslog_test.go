@@ -0,0 +1,174 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syslogServer 接收TCP syslog消息的测试服务器, 按octet-counting分帧后发送到返回的channel
+func syslogServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					var n int
+					if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+						return
+					}
+					msg := make([]byte, n)
+					if _, err := io.ReadFull(r, msg); err != nil {
+						return
+					}
+					lines <- string(msg)
+				}
+			}()
+		}
+	}()
+	return "tcp://" + ln.Addr().String(), lines
+}
+
+func TestSyslogRemote(t *testing.T) {
+	addr, lines := syslogServer(t)
+	// 重新初始化时输出的info日志不发送
+	initTestLogger(t, &LogConfig{SyslogRemote: addr, DisableFile: true, Level: "warn"})
+
+	tests := []struct {
+		name string
+		log  func()
+		want []string
+	}{
+		{"message", func() { Warn("hello syslog") }, []string{"<12>1 ", " test ", "hello syslog"}},
+		{"fields", func() { Named("db").Errorw("slow query", "ms", 120) }, []string{"<11>1 ", " db ", "[fields@32473 ", ` ms="120"`, "slow query"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+			if err := Drain(time.Second); err != nil {
+				t.Fatalf("Drain: %v", err)
+			}
+			select {
+			case got := <-lines:
+				for _, want := range tt.want {
+					if !strings.Contains(got, want) {
+						t.Errorf("syslog message = %q, want %q", got, want)
+					}
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no syslog message received")
+			}
+		})
+	}
+}
+
+func TestSyslogWriteDoesNotBlock(t *testing.T) {
+	// 没有监听的端口, 每次连接都会失败
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w, err := newSyslogWriter("tcp://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.start()
+	defer w.Close()
+
+	start := time.Now()
+	for i := 0; i < 2*syslogQueueSize; i++ {
+		if _, err := w.Write([]byte("message\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("writes took %v while the server is down", d)
+	}
+	if atomic.LoadUint64(&w.dropped) == 0 {
+		t.Error("dropped = 0, want the overflowing messages counted")
+	}
+}
+
+func TestSyslogQueueFullDrops(t *testing.T) {
+	w, err := newSyslogWriter("udp://127.0.0.1:514")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 未启动时消息只进入队列
+	for i := 0; i < syslogQueueSize+5; i++ {
+		_, _ = w.Write([]byte("message"))
+	}
+	if got := atomic.LoadUint64(&w.dropped); got != 5 {
+		t.Errorf("dropped = %d, want 5", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close before start: %v", err)
+	}
+}
+
+func TestSyslogReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w, err := newSyslogWriter("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.start()
+	defer w.Close()
+
+	_, _ = w.Write([]byte("first\n"))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line, err := bufio.NewReader(conn).ReadString('\n'); err != nil || !strings.HasSuffix(line, "first\n") {
+		t.Fatalf("first message = %q %v", line, err)
+	}
+	// 服务器关闭连接, 写入失败后重新连接
+	conn.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+	deadline := time.After(5 * time.Second)
+	for {
+		_, _ = w.Write([]byte("again\n"))
+		select {
+		case c := <-accepted:
+			defer c.Close()
+			if line, err := bufio.NewReader(c).ReadString('\n'); err != nil || !strings.HasSuffix(line, "again\n") {
+				t.Errorf("message after reconnect = %q %v", line, err)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no reconnect after the server closed the connection")
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// traceLevelColor 控制台trace级别的颜色 灰色, 所有主题相同
+const traceLevelColor = "90"
+
+// enableColorFn 开启控制台颜色, 返回false时控制台不输出颜色, 测试时替换
+var enableColorFn = enableColor
+
+// themes 控制台各级别日志的ANSI颜色, 新增主题只需在此添加
+var themes = map[string]map[zapcore.Level]string{
+	// 深色背景使用高亮颜色
+	"dark": {
+		zapcore.DebugLevel:  "96",
+		zapcore.InfoLevel:   "92",
+		zapcore.WarnLevel:   "93",
+		zapcore.ErrorLevel:  "91",
+		zapcore.DPanicLevel: "1;91",
+		zapcore.PanicLevel:  "1;91",
+		zapcore.FatalLevel:  "1;91",
+	},
+	// 浅色背景避免使用黄色等浅色
+	"light": {
+		zapcore.DebugLevel:  "90",
+		zapcore.InfoLevel:   "34",
+		zapcore.WarnLevel:   "35",
+		zapcore.ErrorLevel:  "31",
+		zapcore.DPanicLevel: "1;31",
+		zapcore.PanicLevel:  "1;31",
+		zapcore.FatalLevel:  "1;31",
+	},
+	// solarized配色, 使用256色
+	"solarized": {
+		zapcore.DebugLevel:  "38;5;37",
+		zapcore.InfoLevel:   "38;5;33",
+		zapcore.WarnLevel:   "38;5;136",
+		zapcore.ErrorLevel:  "38;5;160",
+		zapcore.DPanicLevel: "38;5;125",
+		zapcore.PanicLevel:  "38;5;125",
+		zapcore.FatalLevel:  "1;38;5;160",
+	},
+}
+
+// themeLevelEncoder 获取主题对应的级别编码, 主题不存在时使用默认颜色
+func themeLevelEncoder(theme string) zapcore.LevelEncoder {
+	colors, ok := themes[theme]
+	if !ok {
+		return zapcore.CapitalColorLevelEncoder
+	}
+	colored := make(map[zapcore.Level]string, len(colors))
+	for lvl, color := range colors {
+		colored[lvl] = "\x1b[" + color + "m" + lvl.CapitalString() + "\x1b[0m"
+	}
+	return func(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		if s, ok := colored[lvl]; ok {
+			enc.AppendString(s)
+			return
+		}
+		enc.AppendString(lvl.CapitalString())
+	}
+}
+
+var levelEmoji = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "🐛",
+	zapcore.InfoLevel:   "ℹ️",
+	zapcore.WarnLevel:   "⚠️",
+	zapcore.ErrorLevel:  "❌",
+	zapcore.DPanicLevel: "💥",
+	zapcore.PanicLevel:  "💥",
+	zapcore.FatalLevel:  "💀",
+}
+
+// emojiLevelEncoder 在级别前加上对应的emoji
+func emojiLevelEncoder(encode zapcore.LevelEncoder) zapcore.LevelEncoder {
+	return func(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		emoji, ok := levelEmoji[lvl]
+		if !ok {
+			encode(lvl, enc)
+			return
+		}
+		encode(lvl, prefixArrayEncoder{PrimitiveArrayEncoder: enc, prefix: emoji + " "})
+	}
+}
+
+// prefixArrayEncoder 为追加的字符串加上前缀
+type prefixArrayEncoder struct {
+	zapcore.PrimitiveArrayEncoder
+	prefix string
+}
+
+func (e prefixArrayEncoder) AppendString(s string) {
+	e.PrimitiveArrayEncoder.AppendString(e.prefix + s)
+}
@@ -0,0 +1,83 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+// encodeLevel 使用encoder编码lvl, 返回编码后的字符串
+func encodeLevel(t *testing.T, encoder zapcore.LevelEncoder, lvl zapcore.Level) string {
+	t.Helper()
+	enc := zapcore.NewMapObjectEncoder()
+	_ = enc.AddArray("level", zapcore.ArrayMarshalerFunc(func(ae zapcore.ArrayEncoder) error {
+		encoder(lvl, ae)
+		return nil
+	}))
+	got, _ := enc.Fields["level"].([]interface{})
+	if len(got) != 1 {
+		t.Fatalf("encoded = %v, want one value", got)
+	}
+	s, _ := got[0].(string)
+	return s
+}
+
+func TestThemeLevelEncoder(t *testing.T) {
+	tests := []struct {
+		theme string
+		lvl   zapcore.Level
+		want  string
+	}{
+		{"dark", zapcore.InfoLevel, "\x1b[92mINFO\x1b[0m"},
+		{"dark", zapcore.FatalLevel, "\x1b[1;91mFATAL\x1b[0m"},
+		{"light", zapcore.WarnLevel, "\x1b[35mWARN\x1b[0m"},
+		{"light", zapcore.DebugLevel, "\x1b[90mDEBUG\x1b[0m"},
+		{"solarized", zapcore.ErrorLevel, "\x1b[38;5;160mERROR\x1b[0m"},
+		{"solarized", zapcore.PanicLevel, "\x1b[38;5;125mPANIC\x1b[0m"},
+		// 主题中没有的级别不加颜色
+		{"dark", TraceLevel, TraceLevel.CapitalString()},
+		// 主题不存在时使用zap的默认颜色
+		{"", zapcore.InfoLevel, "\x1b[34mINFO\x1b[0m"},
+		{"unknown", zapcore.ErrorLevel, "\x1b[31mERROR\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.theme+" "+tt.lvl.String(), func(t *testing.T) {
+			if got := encodeLevel(t, themeLevelEncoder(tt.theme), tt.lvl); got != tt.want {
+				t.Errorf("themeLevelEncoder(%q)(%s) = %q, want %q", tt.theme, tt.lvl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemesCoverLevels(t *testing.T) {
+	for name, colors := range themes {
+		for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+			if colors[lvl] == "" {
+				t.Errorf("theme %s has no color for %s", name, lvl)
+			}
+		}
+	}
+}
+
+func TestEmojiLevelEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder zapcore.LevelEncoder
+		lvl     zapcore.Level
+		want    string
+	}{
+		{"plain info", zapcore.CapitalLevelEncoder, zapcore.InfoLevel, "ℹ️ INFO"},
+		{"plain error", zapcore.CapitalLevelEncoder, zapcore.ErrorLevel, "❌ ERROR"},
+		{"plain fatal", zapcore.CapitalLevelEncoder, zapcore.FatalLevel, "💀 FATAL"},
+		{"colored warn", themeLevelEncoder("dark"), zapcore.WarnLevel, "⚠️ \x1b[93mWARN\x1b[0m"},
+		{"colored debug", themeLevelEncoder("light"), zapcore.DebugLevel, "🐛 \x1b[90mDEBUG\x1b[0m"},
+		// 没有emoji的级别不加前缀
+		{"no emoji", zapcore.CapitalLevelEncoder, TraceLevel, TraceLevel.CapitalString()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeLevel(t, emojiLevelEncoder(tt.encoder), tt.lvl); got != tt.want {
+				t.Errorf("emojiLevelEncoder(%s) = %q, want %q", tt.lvl, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"time"
+)
+
+func noop() {}
+
+// TraceFunc 以debug级别输出进入函数的日志和参数, 返回的函数输出退出日志和耗时
+// debug级别未开启时不输出任何日志
+//
+//	defer logs.TraceFunc("handleOrder", orderID)()
+func TraceFunc(name string, args ...interface{}) func() {
+	if !l().Desugar().Core().Enabled(zapcore.DebugLevel) {
+		return noop
+	}
+	start := time.Now()
+	l().Debugw("enter "+name, "args", args)
+	return func() {
+		l().Debugw("exit "+name, "elapsed", time.Since(start))
+	}
+}
+
+// MemTrace 记录代码块执行期间的内存分配, 返回的函数以debug级别输出分配的字节数、对象数和GC次数
+// ReadMemStats会暂停程序, debug级别未开启时不做任何事
+//
+//	defer logs.MemTrace("buildIndex")()
+func MemTrace(name string) func() {
+	if !l().Desugar().Core().Enabled(zapcore.DebugLevel) {
+		return noop
+	}
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	return func() {
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		l().Debugw("memtrace "+name,
+			"alloc_bytes", after.TotalAlloc-before.TotalAlloc,
+			"mallocs", after.Mallocs-before.Mallocs,
+			"heap_alloc_delta", int64(after.HeapAlloc)-int64(before.HeapAlloc),
+			"num_gc", after.NumGC-before.NumGC,
+		)
+	}
+}
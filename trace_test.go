@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestTraceFunc(t *testing.T) {
+	tests := []struct {
+		level string
+		want  int
+	}{
+		{"trace", 2},
+		{"debug", 2},
+		{"info", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			recorded := observeDefault(t, tt.level)
+			func() {
+				defer TraceFunc("handleOrder", "o-1", 2)()
+				time.Sleep(10 * time.Millisecond)
+			}()
+
+			entries := recorded.All()
+			if len(entries) != tt.want {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			enter, exit := entries[0], entries[1]
+			if enter.Message != "enter handleOrder" || exit.Message != "exit handleOrder" {
+				t.Errorf("messages = %q %q, want enter and exit handleOrder", enter.Message, exit.Message)
+			}
+			args, _ := enter.ContextMap()["args"].([]interface{})
+			if len(args) != 2 || args[0] != "o-1" || args[1] != 2 {
+				t.Errorf("args = %v, want [o-1 2]", enter.ContextMap()["args"])
+			}
+			if elapsed, ok := exit.ContextMap()["elapsed"].(time.Duration); !ok || elapsed < 10*time.Millisecond {
+				t.Errorf("elapsed = %v, want a duration of at least 10ms", exit.ContextMap()["elapsed"])
+			}
+		})
+	}
+}
+
+// memTraceSink 防止测试中的分配被编译器优化掉
+var memTraceSink [][]byte
+
+func TestMemTrace(t *testing.T) {
+	tests := []struct {
+		level string
+		want  int
+	}{
+		{"debug", 1},
+		{"info", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			recorded := observeDefault(t, tt.level)
+			func() {
+				defer MemTrace("buildIndex")()
+				for i := 0; i < 100; i++ {
+					memTraceSink = append(memTraceSink, make([]byte, 1024))
+				}
+				runtime.GC()
+			}()
+			memTraceSink = nil
+
+			entries := recorded.All()
+			if len(entries) != tt.want {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			e := entries[0]
+			if e.Message != "memtrace buildIndex" || e.Level != zapcore.DebugLevel {
+				t.Errorf("entry = %s %q, want debug memtrace buildIndex", e.Level, e.Message)
+			}
+			fields := e.ContextMap()
+			if got, _ := fields["alloc_bytes"].(uint64); got < 100*1024 {
+				t.Errorf("alloc_bytes = %v, want at least %d", fields["alloc_bytes"], 100*1024)
+			}
+			if got, _ := fields["mallocs"].(uint64); got < 100 {
+				t.Errorf("mallocs = %v, want at least 100", fields["mallocs"])
+			}
+			if got, _ := fields["num_gc"].(uint32); got < 1 {
+				t.Errorf("num_gc = %v, want at least 1", fields["num_gc"])
+			}
+			if _, ok := fields["heap_alloc_delta"].(int64); !ok {
+				t.Errorf("heap_alloc_delta = %v (%T), want an int64", fields["heap_alloc_delta"], fields["heap_alloc_delta"])
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sync/atomic"
+)
+
+// EntryTransformer 日志条目转换函数, 可修改条目和字段, 返回false时丢弃该条目
+type EntryTransformer func(entry zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool)
+
+var transformer atomic.Value
+
+// SetEntryTransformer 设置日志写入前的转换函数, fn为nil时取消
+//
+// 设置后每条日志都会经过fn处理, 并按处理后的级别重新选择输出,
+// 会带来额外的内存分配, 对性能敏感的场景需谨慎使用.
+// 通过With添加的字段已经编码, fn中无法获取
+func SetEntryTransformer(fn func(entry zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool)) {
+	transformer.Store(EntryTransformer(fn))
+}
+
+func entryTransformer() EntryTransformer {
+	fn, _ := transformer.Load().(EntryTransformer)
+	return fn
+}
+
+// transformCore 在写入前调用EntryTransformer处理日志条目
+type transformCore struct {
+	zapcore.Core
+}
+
+func (c transformCore) With(fields []zapcore.Field) zapcore.Core {
+	return transformCore{c.Core.With(fields)}
+}
+
+func (c transformCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entryTransformer() == nil {
+		return c.Core.Check(ent, ce)
+	}
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c transformCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if fn := entryTransformer(); fn != nil {
+		var ok bool
+		if ent, fields, ok = fn(ent, fields); !ok {
+			return nil
+		}
+	}
+	writeRouted(c.Core, ent, fields)
+	return nil
+}
+
+// writeRouted 按条目级别重新选择core写入, 写入错误输出到stderr
+func writeRouted(core zapcore.Core, ent zapcore.Entry, fields []zapcore.Field) {
+	ce := core.Check(ent, nil)
+	if ce == nil {
+		return
+	}
+	ce.ErrorOutput = zapcore.Lock(os.Stderr)
+	ce.Write(fields...)
+}
+
+// maxFieldsCore 单条日志的字段数超过max时截断, 并追加fields_truncated字段记录截断的数量
+// 通过With附加的字段同样计数, 超出的部分在With时丢弃
+type maxFieldsCore struct {
+	zapcore.Core
+	max int
+	// with 已通过With附加的字段数, dropped With时丢弃的字段数
+	with, dropped int
+}
+
+func (c maxFieldsCore) With(fields []zapcore.Field) zapcore.Core {
+	if remaining := c.max - c.with; len(fields) > remaining {
+		c.dropped += len(fields) - remaining
+		fields = fields[:remaining]
+	}
+	c.with += len(fields)
+	c.Core = c.Core.With(fields)
+	return c
+}
+
+func (c maxFieldsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c maxFieldsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	truncated := c.dropped
+	if limit := c.max - c.with; len(fields) > limit {
+		truncated += len(fields) - limit
+		fields = fields[:limit:limit]
+	}
+	if truncated > 0 {
+		fields = append(fields, zap.Int("fields_truncated", truncated))
+	}
+	writeRouted(c.Core, ent, fields)
+	return nil
+}
@@ -0,0 +1,114 @@
+package logs
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetEntryTransformer(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(zapcore.Entry, []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool)
+		main    []string
+		errFile []string
+		absent  []string
+	}{
+		{"nil", nil, []string{`"msg":"original"`, `"k":"v"`}, nil, []string{"rewritten"}},
+		{"rewrite message and fields", func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool) {
+			ent.Message = "rewritten"
+			return ent, append(fields, zap.Bool("transformed", true)), true
+		}, []string{`"msg":"rewritten"`, `"k":"v","transformed":true`}, nil, []string{"original"}},
+		{"raise level", func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool) {
+			ent.Level = zapcore.ErrorLevel
+			return ent, fields, true
+		}, []string{`"level":"ERROR"`}, []string{`"msg":"original"`}, nil},
+		{"drop", func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool) {
+			return ent, fields, false
+		}, nil, nil, []string{"original"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json", StacktraceLevel: "disabled"})
+			SetEntryTransformer(tt.fn)
+			defer SetEntryTransformer(nil)
+			Infow("original", "k", "v")
+			_ = Sync()
+
+			main := readLog(t, filepath.Join(dir, "test.log"))
+			errFile := readLog(t, filepath.Join(dir, "test_err.log"))
+			for _, want := range tt.main {
+				if !strings.Contains(main, want) {
+					t.Errorf("main file = %s, want %s", main, want)
+				}
+			}
+			for _, want := range tt.errFile {
+				if !strings.Contains(errFile, want) {
+					t.Errorf("error file = %s, want %s", errFile, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(main+errFile, absent) {
+					t.Errorf("logs = %s%s, want no %s", main, errFile, absent)
+				}
+			}
+		})
+	}
+}
+
+func TestSetEntryTransformerReset(t *testing.T) {
+	dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json"})
+	SetEntryTransformer(func(ent zapcore.Entry, fields []zapcore.Field) (zapcore.Entry, []zapcore.Field, bool) {
+		return ent, fields, false
+	})
+	Info("dropped line")
+	SetEntryTransformer(nil)
+	Info("kept line")
+	_ = Sync()
+
+	got := readLog(t, filepath.Join(dir, "test.log"))
+	if strings.Contains(got, "dropped line") || !strings.Contains(got, "kept line") {
+		t.Errorf("log = %s, want only the line after reset", got)
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func()
+		want   string
+		absent []string
+	}{
+		{"under the limit", func() { Infow("fields line", "a", 1, "b", 2) }, `"a":1,"b":2}`, []string{"fields_truncated"}},
+		{"at the limit", func() { Infow("fields line", "a", 1, "b", 2, "c", 3) }, `"a":1,"b":2,"c":3}`, []string{"fields_truncated"}},
+		{"over the limit", func() { Infow("fields line", "a", 1, "b", 2, "c", 3, "d", 4, "e", 5) }, `"a":1,"b":2,"c":3,"fields_truncated":2}`, []string{`"d"`, `"e"`}},
+		{"With under the limit", func() { With("a", 1).Infow("fields line", "b", 2) }, `"a":1,"b":2}`, []string{"fields_truncated"}},
+		{"With and call over the limit", func() { With("a", 1, "b", 2).Infow("fields line", "c", 3, "d", 4) }, `"a":1,"b":2,"c":3,"fields_truncated":1}`, []string{`"d"`}},
+		{"With over the limit", func() { With("a", 1, "b", 2, "c", 3, "d", 4).Infow("fields line", "e", 5) }, `"a":1,"b":2,"c":3,"fields_truncated":2}`, []string{`"d"`, `"e"`}},
+		{"nested With", func() { With("a", 1, "b", 2).With("c", 3, "d", 4).Infow("fields line") }, `"a":1,"b":2,"c":3,"fields_truncated":1}`, []string{`"d"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info", Encoding: "json", MaxFields: 3})
+			tt.log()
+			_ = Sync()
+
+			var got string
+			for _, line := range strings.Split(readLog(t, filepath.Join(dir, "test.log")), "\n") {
+				if strings.Contains(line, "fields line") {
+					got = line
+				}
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("log = %s, want %s", got, tt.want)
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(got, absent) {
+					t.Errorf("log = %s, want no %s", got, absent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelNames Level可使用的值
+const levelNames = "trace debug info warn error dpanic panic fatal"
+
+// validateConfig 检查配置, 错误信息中包含出错的字段名
+func validateConfig(c *LogConfig) error {
+	if c == nil {
+		return fmt.Errorf("logs: LogConfig is nil")
+	}
+	if c.FileName == "" {
+		return fmt.Errorf("logs: FileName is empty")
+	}
+	lvl, err := parseLevel(c.Level)
+	if err != nil {
+		return fmt.Errorf("logs: invalid Level %q, accepted values: %s", c.Level, levelNames)
+	}
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"ConsoleLevel", c.ConsoleLevel},
+		{"FileLevel", c.FileLevel},
+	} {
+		if _, err := parseLevel(f.value); f.value != "" && err != nil {
+			return fmt.Errorf("logs: invalid %s %q, accepted values: %s", f.name, f.value, levelNames)
+		}
+	}
+	if c.ErrFileLevel != "" {
+		errLvl, err := parseLevel(c.ErrFileLevel)
+		if err != nil {
+			return fmt.Errorf("logs: invalid ErrFileLevel %q, accepted values: %s", c.ErrFileLevel, levelNames)
+		}
+		if errLvl < lvl {
+			return fmt.Errorf("logs: ErrFileLevel %q is below Level %q", c.ErrFileLevel, c.Level)
+		}
+	}
+	for name, level := range c.Levels {
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("logs: invalid Levels[%q] %q, accepted values: %s", name, level, levelNames)
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value int64
+	}{
+		{"MaxAge", int64(c.MaxAge)},
+		{"MaxSize", int64(c.MaxSize)},
+		{"MaxBackups", int64(c.MaxBackups)},
+		{"MaxFields", int64(c.MaxFields)},
+		{"CallerSkip", int64(c.CallerSkip)},
+		{"PeriodicFsync", int64(c.PeriodicFsync)},
+	} {
+		if f.value < 0 {
+			return fmt.Errorf("logs: %s must not be negative, got %d", f.name, f.value)
+		}
+	}
+	if c.TimeLayout != "" && strings.TrimSpace(c.TimeLayout) == "" {
+		return fmt.Errorf("logs: TimeLayout is blank")
+	}
+	switch c.Encoding {
+	case "", "console", "json", "bigquery":
+	default:
+		return fmt.Errorf("logs: invalid Encoding %q, accepted values: console json bigquery", c.Encoding)
+	}
+	if c.StacktraceLevel != "" && c.StacktraceLevel != "disabled" {
+		if _, err := parseLevel(c.StacktraceLevel); err != nil {
+			return fmt.Errorf("logs: invalid StacktraceLevel %q, accepted values: disabled %s", c.StacktraceLevel, levelNames)
+		}
+	}
+	switch c.RotateInterval {
+	case "", "daily", "hourly":
+	default:
+		return fmt.Errorf("logs: invalid RotateInterval %q, accepted values: daily hourly", c.RotateInterval)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package logs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitLogSettingInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *LogConfig
+		want string
+	}{
+		{"nil", nil, "LogConfig is nil"},
+		{"empty FileName", &LogConfig{Level: "info"}, "FileName"},
+		{"typo in Level", &LogConfig{FileName: "x", Level: "infoo"}, `invalid Level "infoo", accepted values: trace debug`},
+		{"ConsoleLevel", &LogConfig{FileName: "x", ConsoleLevel: "loud"}, "ConsoleLevel"},
+		{"ErrFileLevel below Level", &LogConfig{FileName: "x", Level: "error", ErrFileLevel: "warn"}, "ErrFileLevel"},
+		{"Levels", &LogConfig{FileName: "x", Levels: map[string]string{"db": "x"}}, `Levels["db"]`},
+		{"negative MaxAge", &LogConfig{FileName: "x", MaxAge: -1}, "MaxAge must not be negative"},
+		{"Encoding", &LogConfig{FileName: "x", Encoding: "xml"}, "Encoding"},
+		{"StacktraceLevel", &LogConfig{FileName: "x", StacktraceLevel: "never"}, "StacktraceLevel"},
+		{"RotateInterval", &LogConfig{FileName: "x", RotateInterval: "weekly"}, "RotateInterval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initTestLogger(t, &LogConfig{Level: "info"})
+			if tt.conf != nil {
+				tt.conf.Dir = t.TempDir()
+			}
+			err := InitLogSetting(tt.conf)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("InitLogSetting error = %v, want %q", err, tt.want)
+			}
+
+			// 配置无效时之前的日志配置保持不变
+			Info("still configured")
+			_ = Sync()
+			if got := readLog(t, filepath.Join(dir, "test.log")); !strings.Contains(got, "still configured") {
+				t.Errorf("log file = %q, want the previous logger to keep writing", got)
+			}
+			if got := GetLogConf().Dir; got != dir {
+				t.Errorf("GetLogConf().Dir = %q, want %q", got, dir)
+			}
+		})
+	}
+}
+
+func TestMustInitLogSettingPanics(t *testing.T) {
+	initTestLogger(t, &LogConfig{})
+	defer func() {
+		if x := recover(); x == nil {
+			t.Error("MustInitLogSetting did not panic on an invalid Level")
+		}
+	}()
+	MustInitLogSetting(&LogConfig{FileName: "x", Level: "infoo"})
+}
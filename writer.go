@@ -0,0 +1,65 @@
+package logs
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxWriterLine Writer缓冲的最大长度, 超过时不等换行直接输出
+const maxWriterLine = 64 * 1024
+
+// Writer 获取以level级别输出日志的io.Writer, 每行输出一条日志, 去掉行尾的空白, 空行不输出
+// 没有换行的内容缓冲到下一次写入, 缓冲超过64KB时直接输出, level无效时使用info, 可并发使用
+//
+//	server := &http.Server{ErrorLog: log.New(logs.Writer("error"), "", 0)}
+func Writer(level string) io.Writer {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		lvl = zapcore.InfoLevel
+	}
+	return &lineWriter{level: lvl}
+}
+
+type lineWriter struct {
+	level zapcore.Level
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf.Write(p)
+			if w.buf.Len() >= maxWriterLine {
+				w.flush()
+			}
+			break
+		}
+		w.buf.Write(p[:i])
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush 输出缓冲中的内容并清空缓冲
+func (w *lineWriter) flush() {
+	line := strings.TrimRightFunc(w.buf.String(), unicode.IsSpace)
+	w.buf.Reset()
+	if line == "" {
+		return
+	}
+	// 调用方为io.Writer的使用者, 调用位置没有意义
+	l().WithOptions(zap.WithCaller(false)).Log(w.level, line)
+}
@@ -0,0 +1,69 @@
+package logs
+
+import (
+	"go.uber.org/zap/zapcore"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  string
+		writes []string
+		want   []string
+		wantLv zapcore.Level
+	}{
+		{"single line", "warn", []string{"one\n"}, []string{"one"}, zapcore.WarnLevel},
+		{"multi line", "info", []string{"one\ntwo\n\nthree\n"}, []string{"one", "two", "three"}, zapcore.InfoLevel},
+		{"split writes", "info", []string{"par", "tial\nne", "xt\n"}, []string{"partial", "next"}, zapcore.InfoLevel},
+		{"trailing whitespace", "error", []string{"spaced \t\r\n"}, []string{"spaced"}, zapcore.ErrorLevel},
+		{"pending without newline", "info", []string{"done\n", "pending"}, []string{"done"}, zapcore.InfoLevel},
+		{"invalid level", "loud", []string{"x\n"}, []string{"x"}, zapcore.InfoLevel},
+		{"long line flushed", "info", []string{strings.Repeat("a", maxWriterLine)}, []string{strings.Repeat("a", maxWriterLine)}, zapcore.InfoLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorded := observeDefault(t, "debug")
+			w := Writer(tt.level)
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write = %d, %v", n, err)
+				}
+			}
+			var got []string
+			for _, e := range recorded.All() {
+				got = append(got, e.Message)
+				if e.Level != tt.wantLv {
+					t.Errorf("level = %s, want %s", e.Level, tt.wantLv)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	recorded := observeDefault(t, "debug")
+	w := Writer("info")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 每次写入完整的行, 并发写入不会混在一起
+			l := log.New(w, "", 0)
+			for j := 0; j < 50; j++ {
+				l.Print("concurrent line")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := recorded.FilterMessage("concurrent line").Len(); n != 400 {
+		t.Errorf("entries = %d, want 400", n)
+	}
+}